@@ -0,0 +1,190 @@
+// Package blake3 implements the BLAKE3 hash function, in its hash and keyed
+// hash modes, with 256-bit output.
+//
+// It is a straightforward port of the BLAKE3 reference implementation, meant
+// for authenticating packets, not for bulk hashing.
+package blake3
+
+import "encoding/binary"
+
+// Size is the size of a BLAKE3 hash, in octets.
+const Size = 32
+
+// KeySize is the size of a BLAKE3 key, in octets.
+const KeySize = 32
+
+const (
+	chunkLen = 1024
+	blockLen = 64
+)
+
+const (
+	chunkStart = 1 << iota
+	chunkEnd
+	parent
+	root
+	keyedHash
+)
+
+var iv = [8]uint32{
+	0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A,
+	0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19,
+}
+
+var msgPermutation = [16]int{
+	2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8,
+}
+
+func rotr(x uint32, n uint) uint32 {
+	return x>>n | x<<(32-n)
+}
+
+func g(s *[16]uint32, a, b, c, d int, mx, my uint32) {
+	s[a] += s[b] + mx
+	s[d] = rotr(s[d]^s[a], 16)
+	s[c] += s[d]
+	s[b] = rotr(s[b]^s[c], 12)
+	s[a] += s[b] + my
+	s[d] = rotr(s[d]^s[a], 8)
+	s[c] += s[d]
+	s[b] = rotr(s[b]^s[c], 7)
+}
+
+func round(s *[16]uint32, m *[16]uint32) {
+	// Columns.
+	g(s, 0, 4, 8, 12, m[0], m[1])
+	g(s, 1, 5, 9, 13, m[2], m[3])
+	g(s, 2, 6, 10, 14, m[4], m[5])
+	g(s, 3, 7, 11, 15, m[6], m[7])
+	// Diagonals.
+	g(s, 0, 5, 10, 15, m[8], m[9])
+	g(s, 1, 6, 11, 12, m[10], m[11])
+	g(s, 2, 7, 8, 13, m[12], m[13])
+	g(s, 3, 4, 9, 14, m[14], m[15])
+}
+
+// compress is the BLAKE3 compression function; it returns the first 8 words
+// of its output, which is all a 256-bit hash needs.
+func compress(
+	cv *[8]uint32, block *[16]uint32, counter uint64, length uint32,
+	flags uint32,
+) (out [8]uint32) {
+	s := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		iv[0], iv[1], iv[2], iv[3],
+		uint32(counter), uint32(counter >> 32), length, flags,
+	}
+	m := *block
+	for r := 0; r < 7; r++ {
+		round(&s, &m)
+		if r < 6 {
+			var permuted [16]uint32
+			for i, j := range msgPermutation {
+				permuted[i] = m[j]
+			}
+			m = permuted
+		}
+	}
+	for i := range out {
+		out[i] = s[i] ^ s[i+8]
+	}
+	return
+}
+
+// output is a compression not yet performed, so that the caller can still
+// add the root flag.
+type output struct {
+	cv      [8]uint32
+	block   [16]uint32
+	counter uint64
+	length  uint32
+	flags   uint32
+}
+
+func (o *output) chainingValue() [8]uint32 {
+	return compress(&o.cv, &o.block, o.counter, o.length, o.flags)
+}
+
+func (o *output) rootHash() (h [Size]byte) {
+	words := compress(&o.cv, &o.block, 0, o.length, o.flags|root)
+	for i, w := range words {
+		binary.LittleEndian.PutUint32(h[4*i:], w)
+	}
+	return
+}
+
+func blockWords(b []byte) (words [16]uint32) {
+	var padded [blockLen]byte
+	copy(padded[:], b)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(padded[4*i:])
+	}
+	return
+}
+
+// chunkOutput compresses all blocks but the last of the given chunk, and
+// returns the output of the last.
+func chunkOutput(
+	key *[8]uint32, chunk []byte, counter uint64, flags uint32,
+) (o output) {
+	cv := *key
+	start := uint32(chunkStart)
+	for len(chunk) > blockLen {
+		block := blockWords(chunk[:blockLen])
+		cv = compress(&cv, &block, counter, blockLen, flags|start)
+		chunk = chunk[blockLen:]
+		start = 0
+	}
+	return output{
+		cv:      cv,
+		block:   blockWords(chunk),
+		counter: counter,
+		length:  uint32(len(chunk)),
+		flags:   flags | start | chunkEnd,
+	}
+}
+
+func parentOutput(key *[8]uint32, left, right [8]uint32, flags uint32) output {
+	o := output{cv: *key, length: blockLen, flags: flags | parent}
+	copy(o.block[:8], left[:])
+	copy(o.block[8:], right[:])
+	return o
+}
+
+func hash(key *[8]uint32, flags uint32, data []byte) [Size]byte {
+	var stack [][8]uint32
+	counter := uint64(0)
+	for len(data) > chunkLen {
+		o := chunkOutput(key, data[:chunkLen], counter, flags)
+		cv := o.chainingValue()
+		data = data[chunkLen:]
+		counter++
+		// Merge completed subtrees: as many as trailing zero bits of the
+		// number of chunks so far.
+		for total := counter; total&1 == 0; total >>= 1 {
+			o := parentOutput(key, stack[len(stack)-1], cv, flags)
+			cv = o.chainingValue()
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, cv)
+	}
+	o := chunkOutput(key, data, counter, flags)
+	for i := len(stack) - 1; i >= 0; i-- {
+		o = parentOutput(key, stack[i], o.chainingValue(), flags)
+	}
+	return o.rootHash()
+}
+
+// Sum256 returns the BLAKE3 hash of data.
+func Sum256(data []byte) [Size]byte {
+	return hash(&iv, 0, data)
+}
+
+// KeyedSum256 returns the BLAKE3 keyed hash of data, a MAC under key.
+func KeyedSum256(key *[KeySize]byte, data []byte) [Size]byte {
+	var words [8]uint32
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+	return hash(&words, keyedHash, data)
+}
@@ -0,0 +1,58 @@
+package blake3
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// input returns the test input of the official BLAKE3 test vectors: octets
+// counting up modulo 251.
+func input(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func TestSum256(t *testing.T) {
+	for _, test := range []struct {
+		data []byte
+		want string
+	}{
+		{nil, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{[]byte("abc"), "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+	} {
+		h := Sum256(test.data)
+		if got := hex.EncodeToString(h[:]); got != test.want {
+			t.Errorf("Sum256(%q) = %s, want %s", test.data, got, test.want)
+		}
+	}
+}
+
+func TestKeyedSum256(t *testing.T) {
+	var key [KeySize]byte
+	copy(key[:], "whats the Elvish word for friend")
+	h := KeyedSum256(&key, nil)
+	want := "92b2b75604ed3c761f9d6f62392c8a9227ad0ea3f09573e783f1498a4ed60d26"
+	if got := hex.EncodeToString(h[:]); got != want {
+		t.Errorf("KeyedSum256(empty) = %s, want %s", got, want)
+	}
+}
+
+// TestChunks checks inputs of more than one chunk, which hash as a tree.
+func TestChunks(t *testing.T) {
+	for _, test := range []struct {
+		n    int
+		want string
+	}{
+		{1024, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+		{1025, "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444"},
+		{2048, "e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a"},
+	} {
+		h := Sum256(input(test.n))
+		if got := hex.EncodeToString(h[:]); got != test.want {
+			t.Errorf("Sum256 of %d octets = %s, want %s", test.n, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package blockcodec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/internal/readyblockchan"
+	"github.com/harmony-one/go-raptorq/internal/sourceobject"
+	"github.com/harmony-one/go-raptorq/internal/symbolcount"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// decoderBlock is the decoding state of one source block.
+//
+// A source block goes through the following states:
+//
+//	decoding ──(enough symbols)──▶ ready ──(FreeSourceBlock)──▶ freed
+//	    │
+//	    └──(EndOfInput)──▶ ended, with source zero-filled if asked
+type decoderBlock struct {
+	dec      BlockDecoder // nil once ready or ended
+	received [][]byte     // source symbols received, by ESI
+	source   [][]byte     // source symbols, once ready or zero-filled
+	ready    bool
+	freed    bool
+}
+
+// Decoder is a raptorq.Decoder that decodes each source block of its layout
+// with a block decoder of its scheme.
+//
+// Decoder decodes synchronously: a source block becomes ready within the
+// Decode or DecodeBatch call that feeds its last needed symbol.
+type Decoder struct {
+	*Layout
+
+	scheme Scheme
+	mutex  sync.Mutex
+	blocks []decoderBlock
+	rbcs   readyblockchan.ReadyBlockChannels
+	counts symbolcount.Counters
+	closed bool
+}
+
+// NewDecoder returns a decoder of a source object laid out as given, using
+// the given scheme.
+func NewDecoder(scheme Scheme, layout *Layout) (dec *Decoder, err error) {
+	dec = &Decoder{
+		Layout: layout,
+		scheme: scheme,
+		blocks: make([]decoderBlock, layout.NumSourceBlocks()),
+	}
+	for sbn := range dec.blocks {
+		k := layout.NumSourceSymbols(uint8(sbn))
+		b := &dec.blocks[sbn]
+		if b.dec, err = scheme.NewBlockDecoder(k, layout.SymbolSize()); err != nil {
+			dec = nil
+			return
+		}
+		b.received = make([][]byte, k)
+	}
+	dec.rbcs.Reset(layout.NumSourceBlocks())
+	dec.counts.Reset(layout.NumSourceBlocks())
+	return
+}
+
+// checkOpen panics if the decoder has been closed.
+//
+// The caller must hold the mutex.
+func (dec *Decoder) checkOpen() {
+	if dec.closed {
+		panic("decoder already closed")
+	}
+}
+
+// Decode decodes the given encoding symbol.
+func (dec *Decoder) Decode(sbn uint8, esi uint32, symbol []byte) {
+	dec.DecodeBatch([]raptorq.Symbol{{SBN: sbn, ESI: esi, Data: symbol}})
+}
+
+// DecodeBatch decodes the given encoding symbols, and returns the status of
+// each symbol.
+func (dec *Decoder) DecodeBatch(symbols []raptorq.Symbol) (
+	status []raptorq.DecodeStatus,
+) {
+	status = make([]raptorq.DecodeStatus, len(symbols))
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	dec.checkOpen()
+	for i, symbol := range symbols {
+		status[i] = dec.add(symbol)
+	}
+	return
+}
+
+// add adds one encoding symbol, and decodes its source block if possible.
+//
+// The caller must hold the mutex.
+func (dec *Decoder) add(symbol raptorq.Symbol) raptorq.DecodeStatus {
+	sbn, esi := symbol.SBN, symbol.ESI
+	if sbn >= dec.NumSourceBlocks() ||
+		len(symbol.Data) != int(dec.SymbolSize()) ||
+		esi >= dec.scheme.MaxSymbols(dec.NumSourceSymbols(sbn)) {
+		return raptorq.SymbolRejected
+	}
+	b := &dec.blocks[sbn]
+	if b.dec == nil {
+		return raptorq.SymbolNotNeeded
+	}
+	data := append([]byte(nil), symbol.Data...)
+	status := b.dec.Add(esi, data)
+	if status != raptorq.SymbolAccepted {
+		return status
+	}
+	k := dec.NumSourceSymbols(sbn)
+	dec.counts.Add(sbn, esi, k)
+	if esi < uint32(k) {
+		b.received[esi] = data
+	}
+	if dec.counts.Count(sbn) >= uint32(dec.scheme.MinSymbols(k)) {
+		if source := b.dec.Decode(); source != nil {
+			b.dec = nil
+			b.source = source
+			b.ready = true
+			dec.rbcs.AddBlock(sbn)
+		}
+	}
+	return status
+}
+
+// EndOfInput signals no more symbols will be fed for the given source block,
+// and returns which source symbols are known.
+//
+// If fillZeros is true, source symbols that are not known are filled with
+// zeros, so the source block can then be retrieved using SourceBlock.
+func (dec *Decoder) EndOfInput(sbn uint8, fillZeros bool) (
+	known []bool, err error,
+) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	dec.checkOpen()
+	if sbn >= dec.NumSourceBlocks() {
+		err = errors.New("source block number out of range")
+		return
+	}
+	b := &dec.blocks[sbn]
+	known = make([]bool, dec.NumSourceSymbols(sbn))
+	for esi := range known {
+		known[esi] = b.ready || b.received != nil && b.received[esi] != nil
+	}
+	if b.dec == nil {
+		return
+	}
+	b.dec = nil
+	if fillZeros {
+		b.source = make([][]byte, len(known))
+		for esi, symbol := range b.received {
+			if symbol == nil {
+				symbol = make([]byte, dec.SymbolSize())
+			}
+			b.source[esi] = symbol
+		}
+	}
+	return
+}
+
+// NumReceivedSymbols returns the number of encoding symbols accepted so far
+// for the given source block.
+func (dec *Decoder) NumReceivedSymbols(sbn uint8) uint32 {
+	return dec.counts.Count(sbn)
+}
+
+// MissingSourceSymbols returns the ESIs of the source symbols not received
+// for the given source block, or none if the source block is ready.
+func (dec *Decoder) MissingSourceSymbols(sbn uint8) (
+	esis []uint32, err error,
+) {
+	switch {
+	case sbn >= dec.NumSourceBlocks():
+		err = errors.New("source block number out of range")
+	case dec.IsSourceBlockReady(sbn):
+	default:
+		esis = dec.counts.Missing(sbn, dec.NumSourceSymbols(sbn))
+	}
+	return
+}
+
+// IsSourceBlockReady returns whether the given source block is ready.
+func (dec *Decoder) IsSourceBlockReady(sbn uint8) bool {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	return int(sbn) < len(dec.blocks) && dec.blocks[sbn].ready
+}
+
+// IsSourceObjectReady returns whether the entire source object is ready.
+func (dec *Decoder) IsSourceObjectReady() bool {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	for sbn := range dec.blocks {
+		if !dec.blocks[sbn].ready {
+			return false
+		}
+	}
+	return true
+}
+
+// source returns the source symbols of the given source block, if ready or
+// zero-filled.
+//
+// The caller must hold the mutex.
+func (dec *Decoder) source(sbn uint8) (source [][]byte, err error) {
+	dec.checkOpen()
+	switch {
+	case sbn >= dec.NumSourceBlocks():
+		err = errors.New("source block number out of range")
+	case dec.blocks[sbn].freed:
+		err = errors.New("source block already freed")
+	case dec.blocks[sbn].source == nil:
+		err = raptorq.SourceBlockNotReady(sbn)
+	default:
+		source = dec.blocks[sbn].source
+	}
+	return
+}
+
+// recovered returns the source symbols of the given source block, if ready.
+func (dec *Decoder) recovered(sbn uint8) (source [][]byte, err error) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	if sbn < dec.NumSourceBlocks() && !dec.blocks[sbn].ready {
+		err = raptorq.SourceBlockNotReady(sbn)
+		return
+	}
+	return dec.source(sbn)
+}
+
+// SourceBlock retrieves the given source block into the given buffer.
+func (dec *Decoder) SourceBlock(sbn uint8, buf []byte) (n int, err error) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	source, err := dec.source(sbn)
+	if err != nil {
+		return
+	}
+	if len(buf) < int(dec.SourceBlockSize(sbn)) {
+		err = errors.New("decoder buffer too small")
+		return
+	}
+	n = dec.Block(sbn, source, buf)
+	return
+}
+
+// SourceSymbol retrieves the given source symbol into the given buffer.
+//
+// The source symbol can be retrieved as soon as it has been received, even if
+// the rest of the source block is not ready.
+func (dec *Decoder) SourceSymbol(sbn uint8, esi uint32, buf []byte) (
+	n int, err error,
+) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	dec.checkOpen()
+	switch {
+	case esi >= uint32(dec.NumSourceSymbols(sbn)):
+		err = errors.New("source symbol out of range")
+	case len(buf) < int(dec.SymbolSize()):
+		err = errors.New("decoder buffer too small")
+	case dec.blocks[sbn].source != nil:
+		n = copy(buf, dec.blocks[sbn].source[esi])
+	case dec.blocks[sbn].received != nil && dec.blocks[sbn].received[esi] != nil:
+		n = copy(buf, dec.blocks[sbn].received[esi])
+	default:
+		err = raptorq.SourceSymbolNotReady{SBN: sbn, ESI: esi}
+	}
+	return
+}
+
+// SourceObject retrieves the entire source object into the given buffer.
+func (dec *Decoder) SourceObject(buf []byte) (n int, err error) {
+	if len(buf) < int(dec.TransferLength()) {
+		err = errors.New("decoder buffer too small")
+		return
+	}
+	for sbn := 0; sbn < int(dec.NumSourceBlocks()); sbn++ {
+		if !dec.IsSourceBlockReady(uint8(sbn)) {
+			err = raptorq.SourceBlockNotReady(sbn)
+			return
+		}
+		var m int
+		if m, err = dec.SourceBlock(uint8(sbn), buf[n:]); err != nil {
+			return
+		}
+		n += m
+	}
+	return
+}
+
+// ReadAt reads the given range of the source object into the given buffer.
+//
+// ReadAt implements io.ReaderAt.
+// It returns raptorq.SourceBlockNotReady if a source block covering the range
+// has not been decoded yet.
+func (dec *Decoder) ReadAt(p []byte, off int64) (n int, err error) {
+	return sourceobject.ReadAt(dec, nil, p, off)
+}
+
+// Reader returns a reader that streams the source object in order,
+// waiting for each source block to become ready.
+//
+// The reader frees each source block after copying it out.
+func (dec *Decoder) Reader(ctx context.Context) io.Reader {
+	return sourceobject.NewReader(ctx, dec)
+}
+
+// FreeSourceBlock frees the memory used for the given source block.  The
+// source block stays ready, but can no longer be retrieved.
+func (dec *Decoder) FreeSourceBlock(sbn uint8) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	if int(sbn) >= len(dec.blocks) {
+		return
+	}
+	b := &dec.blocks[sbn]
+	b.dec = nil
+	b.received = nil
+	b.source = nil
+	b.freed = true
+}
+
+// Reencoder returns an encoder that generates encoding symbols for the source
+// blocks recovered by this decoder.
+func (dec *Decoder) Reencoder() (enc raptorq.Encoder, err error) {
+	enc = &Encoder{
+		Layout: dec.Layout,
+		scheme: dec.scheme,
+		dec:    dec,
+		blocks: make([]BlockEncoder, dec.NumSourceBlocks()),
+	}
+	return
+}
+
+// AddReadyBlockChan adds a channel through which the decoder notifies the
+// block number of each source block ready.
+//
+// Source blocks already ready at the time of the call are immediately sent
+// to the channel.
+//
+// AddReadyBlockChan returns an error if the channel has already been added.
+func (dec *Decoder) AddReadyBlockChan(ch chan<- uint8) (err error) {
+	return dec.rbcs.AddChannel(ch)
+}
+
+// RemoveReadyBlockChan removes a channel previously registered using
+// AddReadyBlockChan.
+//
+// RemoveReadyBlockChan returns an error if the channel has not yet been added.
+func (dec *Decoder) RemoveReadyBlockChan(ch chan<- uint8) (err error) {
+	return dec.rbcs.RemoveChannel(ch)
+}
+
+// Close closes the decoder.
+func (dec *Decoder) Close() (err error) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	if dec.closed {
+		err = errors.New("decoder already closed")
+		return
+	}
+	dec.closed = true
+	dec.blocks = nil
+	dec.rbcs.Reset(dec.NumSourceBlocks())
+	return
+}
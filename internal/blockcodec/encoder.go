@@ -0,0 +1,142 @@
+package blockcodec
+
+import (
+	"errors"
+	"sync"
+)
+
+// Encoder is a raptorq.Encoder that encodes each source block of its layout
+// with a block encoder of its scheme, created when first needed.
+type Encoder struct {
+	*Layout
+
+	scheme          Scheme
+	maxSubBlockSize uint32
+	input           []byte
+	dec             *Decoder // source of source blocks if re-encoding
+	mutex           sync.Mutex
+	blocks          []BlockEncoder
+	closed          bool
+}
+
+// NewEncoder returns an encoder of the given source object, laid out as
+// given, using the given scheme.
+func NewEncoder(
+	scheme Scheme, layout *Layout, input []byte, maxSubBlockSize uint32,
+) *Encoder {
+	return &Encoder{
+		Layout:          layout,
+		scheme:          scheme,
+		maxSubBlockSize: maxSubBlockSize,
+		input:           input,
+		blocks:          make([]BlockEncoder, layout.NumSourceBlocks()),
+	}
+}
+
+// blockEncoder returns the block encoder of the given source block, creating
+// one if needed.
+func (enc *Encoder) blockEncoder(sbn uint8) (
+	blockEnc BlockEncoder, err error,
+) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if enc.closed {
+		panic("encoder already closed")
+	}
+	if blockEnc = enc.blocks[sbn]; blockEnc != nil {
+		return
+	}
+	var source [][]byte
+	if enc.dec != nil {
+		source, err = enc.dec.recovered(sbn)
+	} else {
+		source = enc.Symbols(sbn, enc.input)
+	}
+	if err != nil {
+		return
+	}
+	if blockEnc, err = enc.scheme.NewBlockEncoder(source); err == nil {
+		enc.blocks[sbn] = blockEnc
+	}
+	return
+}
+
+// Encode writes the encoding symbol identified by the given source block
+// number and encoding symbol ID into buf.
+func (enc *Encoder) Encode(sbn uint8, esi uint32, buf []byte) (
+	written uint, err error,
+) {
+	symbolSize := int(enc.SymbolSize())
+	switch {
+	case len(buf) < symbolSize:
+		err = errors.New("encoder buffer too small")
+	case sbn >= enc.NumSourceBlocks():
+		err = errors.New("source block number out of range")
+	case esi >= enc.scheme.MaxSymbols(enc.NumSourceSymbols(sbn)):
+		err = errors.New("encoding symbol ID out of range")
+	}
+	if err != nil {
+		return
+	}
+	blockEnc, err := enc.blockEncoder(sbn)
+	if err != nil {
+		return
+	}
+	if err = blockEnc.Encode(esi, buf[:symbolSize]); err == nil {
+		written = uint(symbolSize)
+	}
+	return
+}
+
+// MaxSubBlockSize returns the maximum sub-block size, in octets, given to the
+// factory, or the size of the largest sub-block if re-encoding.
+func (enc *Encoder) MaxSubBlockSize() uint32 {
+	if enc.dec != nil {
+		return enc.Layout.MaxSubBlockSize()
+	}
+	return enc.maxSubBlockSize
+}
+
+// FreeSourceBlock frees the block encoder of the given source block.  Encode
+// creates it again if needed.
+func (enc *Encoder) FreeSourceBlock(sbn uint8) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if int(sbn) < len(enc.blocks) {
+		enc.blocks[sbn] = nil
+	}
+}
+
+// MinSymbols returns the number of encoding symbols needed to decode the
+// given source block, or 0 if sbn is out of range.
+func (enc *Encoder) MinSymbols(sbn uint8) uint16 {
+	if sbn >= enc.NumSourceBlocks() {
+		return 0
+	}
+	return enc.scheme.MinSymbols(enc.NumSourceSymbols(sbn))
+}
+
+// MaxSymbols returns the number of encoding symbols that can be generated for
+// the given source block, or 0 if sbn is out of range or, if re-encoding, the
+// source block has not been recovered.
+func (enc *Encoder) MaxSymbols(sbn uint8) uint32 {
+	if sbn >= enc.NumSourceBlocks() ||
+		enc.dec != nil && !enc.dec.IsSourceBlockReady(sbn) {
+		return 0
+	}
+	return enc.scheme.MaxSymbols(enc.NumSourceSymbols(sbn))
+}
+
+// Close closes the encoder.
+func (enc *Encoder) Close() (err error) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if enc.closed {
+		err = errors.New("encoder already closed")
+		return
+	}
+	enc.closed = true
+	enc.blocks = nil
+	enc.input = nil
+	return
+}
@@ -0,0 +1,211 @@
+// Package blockcodec turns a systematic FEC scheme for single source blocks
+// into raptorq.Encoder and raptorq.Decoder implementations, handling the
+// partitioning of the source object into source blocks and sub-blocks of RFC
+// 5052 Section 9.1, which RFC 5053 and RFC 6330 share.
+package blockcodec
+
+import "errors"
+
+// Partition is the function Partition[I, J] of RFC 5052 Section 9.1, which
+// splits I into J pieces of sizes as equal as possible: jl pieces of size il
+// and js pieces of size is.
+func Partition(i, j uint64) (il, is, jl, js uint64) {
+	il = (i + j - 1) / j
+	is = i / j
+	jl = i - is*j
+	js = j - jl
+	return
+}
+
+// Layout is the partitioning of a source object into source blocks of source
+// symbols, and of source symbols into sub-symbols, along with the object
+// transmission information that conveys it.
+//
+// Layout implements raptorq.ObjectInfo.
+type Layout struct {
+	transferLength    uint64
+	symbolSize        uint16
+	numSourceBlocks   uint8
+	numSubBlocks      uint16
+	alignment         uint8
+	commonOTI         uint64
+	schemeSpecificOTI uint32
+
+	kl, ks uint16 // source symbols per long and short source block
+	zl     int    // number of long source blocks
+	tl, ts uint16 // sub-symbol sizes, in octets, of long and short sub-blocks
+	nl     int    // number of long sub-blocks
+}
+
+// NewLayout returns the layout of a source object of the given transfer
+// length into z source blocks of n sub-blocks, for the given symbol size and
+// symbol alignment, conveyed by the given OTIs.
+func NewLayout(
+	transferLength uint64, symbolSize uint16, z uint8, n uint16, al uint8,
+	commonOTI uint64, schemeSpecificOTI uint32,
+) (l *Layout, err error) {
+	switch {
+	case transferLength == 0:
+		err = errors.New("empty source object")
+	case symbolSize == 0 || al == 0 || symbolSize%uint16(al) != 0:
+		err = errors.New("symbol size must be a non-zero multiple of alignment")
+	case z == 0 || n == 0 || uint64(n) > uint64(symbolSize/uint16(al)):
+		err = errors.New("invalid number of source blocks or sub-blocks")
+	}
+	if err != nil {
+		return
+	}
+	kt := (transferLength + uint64(symbolSize) - 1) / uint64(symbolSize)
+	if kt < uint64(z) {
+		err = errors.New("more source blocks than source symbols")
+		return
+	}
+	kl, ks, zl, _ := Partition(kt, uint64(z))
+	if kl > 1<<16-1 {
+		err = errors.New("too many source symbols per source block")
+		return
+	}
+	tl, ts, nl, _ := Partition(uint64(symbolSize/uint16(al)), uint64(n))
+	l = &Layout{
+		transferLength:    transferLength,
+		symbolSize:        symbolSize,
+		numSourceBlocks:   z,
+		numSubBlocks:      n,
+		alignment:         al,
+		commonOTI:         commonOTI,
+		schemeSpecificOTI: schemeSpecificOTI,
+		kl:                uint16(kl),
+		ks:                uint16(ks),
+		zl:                int(zl),
+		tl:                uint16(tl) * uint16(al),
+		ts:                uint16(ts) * uint16(al),
+		nl:                int(nl),
+	}
+	return
+}
+
+// CommonOTI returns the common object transmission information.
+func (l *Layout) CommonOTI() uint64 {
+	return l.commonOTI
+}
+
+// TransferLength returns the size of the source object, in octets.
+func (l *Layout) TransferLength() uint64 {
+	return l.transferLength
+}
+
+// SymbolSize returns the symbol size, in octets.
+func (l *Layout) SymbolSize() uint16 {
+	return l.symbolSize
+}
+
+// SchemeSpecificOTI returns the scheme-specific object transmission
+// information.
+func (l *Layout) SchemeSpecificOTI() uint32 {
+	return l.schemeSpecificOTI
+}
+
+// NumSourceBlocks returns the number of source blocks.
+func (l *Layout) NumSourceBlocks() uint8 {
+	return l.numSourceBlocks
+}
+
+// NumSourceSymbols returns the number of source symbols in the given source
+// block, or 0 if sbn is out of range.
+func (l *Layout) NumSourceSymbols(sbn uint8) uint16 {
+	switch {
+	case sbn >= l.numSourceBlocks:
+		return 0
+	case int(sbn) < l.zl:
+		return l.kl
+	default:
+		return l.ks
+	}
+}
+
+// blockOffset returns the offset of the given source block in the source
+// object, in octets.
+func (l *Layout) blockOffset(sbn uint8) uint64 {
+	long := uint64(sbn)
+	if long > uint64(l.zl) {
+		long = uint64(l.zl)
+	}
+	short := uint64(sbn) - long
+	return (long*uint64(l.kl) + short*uint64(l.ks)) * uint64(l.symbolSize)
+}
+
+// SourceBlockSize returns the size of the given source block, in octets, or 0
+// if sbn is out of range.
+func (l *Layout) SourceBlockSize(sbn uint8) uint32 {
+	if sbn >= l.numSourceBlocks {
+		return 0
+	}
+	start := l.blockOffset(sbn)
+	end := start + uint64(l.NumSourceSymbols(sbn))*uint64(l.symbolSize)
+	if end > l.transferLength {
+		end = l.transferLength
+	}
+	return uint32(end - start)
+}
+
+// NumSubBlocks returns the number of sub-blocks.
+func (l *Layout) NumSubBlocks() uint16 {
+	return l.numSubBlocks
+}
+
+// SymbolAlignmentParameter returns the symbol alignment, in octets.
+func (l *Layout) SymbolAlignmentParameter() uint8 {
+	return l.alignment
+}
+
+// MaxSubBlockSize returns the size of the largest sub-block, in octets.
+func (l *Layout) MaxSubBlockSize() uint32 {
+	return uint32(l.kl) * uint32(l.tl)
+}
+
+// subSymbolSize returns the sub-symbol size of the given sub-block, in octets.
+func (l *Layout) subSymbolSize(j int) int {
+	if j < l.nl {
+		return int(l.tl)
+	}
+	return int(l.ts)
+}
+
+// Symbols splits the given source block, taken from the source object, into
+// its source symbols.  Source symbol i is the concatenation of sub-symbol i of
+// each sub-block, and the end of the source block is padded with zeros.
+func (l *Layout) Symbols(sbn uint8, object []byte) (symbols [][]byte) {
+	k := int(l.NumSourceSymbols(sbn))
+	start := l.blockOffset(sbn)
+	block := make([]byte, k*int(l.symbolSize))
+	copy(block, object[start:start+uint64(l.SourceBlockSize(sbn))])
+	symbols = make([][]byte, k)
+	for i := range symbols {
+		symbols[i] = make([]byte, 0, l.symbolSize)
+	}
+	offset := 0
+	for j := 0; j < int(l.numSubBlocks); j++ {
+		size := l.subSymbolSize(j)
+		for i := range symbols {
+			symbols[i] = append(symbols[i], block[offset:offset+size]...)
+			offset += size
+		}
+	}
+	return
+}
+
+// Block reassembles a source block from its source symbols into buf, which
+// must have room for SourceBlockSize(sbn) octets, and returns the number of
+// octets written.
+func (l *Layout) Block(sbn uint8, symbols [][]byte, buf []byte) int {
+	size := int(l.SourceBlockSize(sbn))
+	n := 0
+	for j, pos := 0, 0; j < int(l.numSubBlocks) && n < size; j++ {
+		sub := l.subSymbolSize(j)
+		for i := 0; i < len(symbols) && n < size; i++ {
+			n += copy(buf[n:size], symbols[i][pos:pos+sub])
+		}
+		pos += sub
+	}
+	return n
+}
@@ -0,0 +1,52 @@
+package blockcodec
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPartition(t *testing.T) {
+	il, is, jl, js := Partition(10, 3)
+	if il != 4 || is != 3 || jl != 1 || js != 2 {
+		t.Errorf("Partition(10, 3) = %d, %d, %d, %d, want 4, 3, 1, 2",
+			il, is, jl, js)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	// 11 symbols of 8 octets: source blocks of 4, 4 and 3 source symbols,
+	// with sub-symbols of 4 and 4 octets.
+	object := make([]byte, 85)
+	for i := range object {
+		object[i] = byte(i)
+	}
+	l, err := NewLayout(uint64(len(object)), 8, 3, 2, 2, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantK := []uint16{4, 4, 3}
+	wantSize := []uint32{32, 32, 21}
+	for sbn := uint8(0); sbn < 3; sbn++ {
+		if k := l.NumSourceSymbols(sbn); k != wantK[sbn] {
+			t.Errorf("NumSourceSymbols(%d) = %d, want %d", sbn, k, wantK[sbn])
+		}
+		if size := l.SourceBlockSize(sbn); size != wantSize[sbn] {
+			t.Errorf("SourceBlockSize(%d) = %d, want %d",
+				sbn, size, wantSize[sbn])
+		}
+	}
+	symbols := l.Symbols(0, object)
+	// Source symbol 1 is sub-symbol 1 of each sub-block.
+	if want := []byte{4, 5, 6, 7, 20, 21, 22, 23}; !bytes.Equal(symbols[1], want) {
+		t.Errorf("source symbol 1 = %v, want %v", symbols[1], want)
+	}
+	var out []byte
+	for sbn := uint8(0); sbn < 3; sbn++ {
+		buf := make([]byte, l.SourceBlockSize(sbn))
+		n := l.Block(sbn, l.Symbols(sbn, object), buf)
+		out = append(out, buf[:n]...)
+	}
+	if !bytes.Equal(out, object) {
+		t.Error("reassembled source object differs")
+	}
+}
@@ -0,0 +1,44 @@
+package blockcodec
+
+import "github.com/harmony-one/go-raptorq/pkg/raptorq"
+
+// Scheme is a systematic FEC scheme that encodes and decodes one source block
+// at a time; the first encoding symbols of a source block are its source
+// symbols.
+type Scheme interface {
+	// NewBlockEncoder returns an encoder for the source block made of the
+	// given source symbols, all of the same size.
+	NewBlockEncoder(source [][]byte) (BlockEncoder, error)
+
+	// NewBlockDecoder returns a decoder for a source block of the given
+	// number of source symbols of the given size.
+	NewBlockDecoder(numSourceSymbols uint16, symbolSize uint16) (
+		BlockDecoder, error)
+
+	// MinSymbols returns the number of encoding symbols needed to decode a
+	// source block of the given number of source symbols, with high
+	// probability.
+	MinSymbols(numSourceSymbols uint16) uint16
+
+	// MaxSymbols returns the number of encoding symbols the scheme can
+	// generate for a source block of the given number of source symbols.
+	MaxSymbols(numSourceSymbols uint16) uint32
+}
+
+// BlockEncoder encodes one source block.
+type BlockEncoder interface {
+	// Encode writes the encoding symbol of the given ESI into buf, which
+	// has room for exactly one symbol.  esi is less than MaxSymbols.
+	Encode(esi uint32, buf []byte) error
+}
+
+// BlockDecoder decodes one source block.
+type BlockDecoder interface {
+	// Add adds the given encoding symbol, of the symbol size, and returns
+	// whether the decoder took it.  esi is less than MaxSymbols.
+	Add(esi uint32, symbol []byte) raptorq.DecodeStatus
+
+	// Decode recovers the source symbols from the encoding symbols added so
+	// far, or returns nil if they do not suffice yet.
+	Decode() (source [][]byte)
+}
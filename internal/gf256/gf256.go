@@ -0,0 +1,92 @@
+// Package gf256 implements arithmetic over the finite field GF(2^8) defined by
+// the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1 (0x11D), as used by RFC
+// 8681 and Reed-Solomon codes.
+package gf256
+
+// Poly is the primitive polynomial of the field.
+const Poly = 0x11D
+
+var (
+	expTable [510]uint8
+	logTable [256]uint8
+	mulTable [256][256]uint8
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		expTable[i] = uint8(x)
+		expTable[i+255] = uint8(x)
+		logTable[x] = uint8(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= Poly
+		}
+	}
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			mulTable[a][b] = expTable[int(logTable[a])+int(logTable[b])]
+		}
+	}
+}
+
+// Exp returns α^n, where α is the primitive element x.
+func Exp(n int) uint8 {
+	n %= 255
+	if n < 0 {
+		n += 255
+	}
+	return expTable[n]
+}
+
+// Mul returns a·b.
+func Mul(a, b uint8) uint8 {
+	return mulTable[a][b]
+}
+
+// Inv returns the multiplicative inverse of a, which must not be zero.
+func Inv(a uint8) uint8 {
+	if a == 0 {
+		panic("gf256: inverse of zero")
+	}
+	return expTable[255-int(logTable[a])]
+}
+
+// Div returns a/b, where b must not be zero.
+func Div(a, b uint8) uint8 {
+	if b == 0 {
+		panic("gf256: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+// MulAdd sets dst to dst + c·src, element-wise.  src must be at least as long
+// as dst.
+func MulAdd(dst []byte, c uint8, src []byte) {
+	switch c {
+	case 0:
+	case 1:
+		for i := range dst {
+			dst[i] ^= src[i]
+		}
+	default:
+		row := &mulTable[c]
+		for i := range dst {
+			dst[i] ^= row[src[i]]
+		}
+	}
+}
+
+// MulSlice sets dst to c·dst, element-wise.
+func MulSlice(dst []byte, c uint8) {
+	if c == 1 {
+		return
+	}
+	row := &mulTable[c]
+	for i := range dst {
+		dst[i] = row[dst[i]]
+	}
+}
@@ -0,0 +1,38 @@
+package gf256
+
+import "testing"
+
+func TestField(t *testing.T) {
+	// x^8 reduces to x^4 + x^3 + x^2 + 1.
+	if got := Mul(0x80, 2); got != 0x1D {
+		t.Errorf("Mul(0x80, 2) = %#x, want 0x1d", got)
+	}
+	for a := 1; a < 256; a++ {
+		if got := Mul(uint8(a), Inv(uint8(a))); got != 1 {
+			t.Fatalf("%d·%d⁻¹ = %d", a, a, got)
+		}
+		for b := 1; b < 256; b++ {
+			if got := Div(Mul(uint8(a), uint8(b)), uint8(b)); got != uint8(a) {
+				t.Fatalf("%d·%d/%d = %d", a, b, b, got)
+			}
+		}
+	}
+	seen := make(map[uint8]bool)
+	for n := 0; n < 255; n++ {
+		seen[Exp(n)] = true
+	}
+	if len(seen) != 255 {
+		t.Errorf("α generates %d elements, want 255", len(seen))
+	}
+}
+
+func TestMulAdd(t *testing.T) {
+	dst := []byte{1, 2, 3}
+	MulAdd(dst, 3, []byte{4, 5, 6})
+	want := []byte{1 ^ Mul(3, 4), 2 ^ Mul(3, 5), 3 ^ Mul(3, 6)}
+	for i := range dst {
+		if dst[i] != want[i] {
+			t.Fatalf("MulAdd() = %v, want %v", dst, want)
+		}
+	}
+}
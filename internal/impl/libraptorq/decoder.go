@@ -1,11 +1,15 @@
 package libraptorq
 
 import (
+	"context"
 	"errors"
+	"io"
 	"runtime"
 
 	"github.com/harmony-one/go-raptorq/internal/impl/libraptorq/swig"
 	"github.com/harmony-one/go-raptorq/internal/readyblockchan"
+	"github.com/harmony-one/go-raptorq/internal/sourceobject"
+	"github.com/harmony-one/go-raptorq/internal/symbolcount"
 	"github.com/harmony-one/go-raptorq/pkg/raptorq"
 )
 
@@ -31,6 +35,7 @@ func (*DecoderFactory) New(commonOTI uint64, schemeSpecificOTI uint32) (
 		dec.commonOTI = commonOTI
 		dec.schemeSpecificOTI = schemeSpecificOTI
 		dec.rbcs.Reset(dec.NumSourceBlocks())
+		dec.counts.Reset(dec.NumSourceBlocks())
 		go dec.readyBlocksLoop()
 		decoder = dec
 		runtime.SetFinalizer(decoder, finalizeDecoder)
@@ -54,6 +59,7 @@ type Decoder struct {
 	commonOTI         uint64
 	schemeSpecificOTI uint32
 	rbcs              readyblockchan.ReadyBlockChannels
+	counts            symbolcount.Counters
 }
 
 // Decoder destroy sequence:
@@ -143,7 +149,108 @@ func (dec *Decoder) SymbolAlignmentParameter() uint8 {
 // so IsSourceObjectReady or IsSourceBlockReady may not immediately return up
 // to date result.
 func (dec *Decoder) Decode(sbn uint8, esi uint32, symbol []byte) {
-	dec.wrapped.Add_symbol(symbol, esi, sbn)
+	if dec.wrapped.Add_symbol(symbol, esi, sbn) == swig.Error_NONE {
+		dec.counts.Add(sbn, esi, dec.NumSourceSymbols(sbn))
+	}
+}
+
+// DecodeBatch decodes the given symbols in a single call into libRaptorQ,
+// and returns the status of each symbol.
+//
+// Symbols whose size does not match the symbol size are rejected without
+// being passed to libRaptorQ.
+func (dec *Decoder) DecodeBatch(symbols []raptorq.Symbol) (
+	status []raptorq.DecodeStatus,
+) {
+	status = make([]raptorq.DecodeStatus, len(symbols))
+	symbolSize := int(dec.SymbolSize())
+	data := make([]byte, 0, len(symbols)*symbolSize)
+	esis := make([]uint32, 0, len(symbols))
+	sbns := make([]byte, 0, len(symbols))
+	indices := make([]int, 0, len(symbols))
+	for i, symbol := range symbols {
+		if len(symbol.Data) != symbolSize {
+			status[i] = raptorq.SymbolRejected
+			continue
+		}
+		data = append(data, symbol.Data...)
+		esis = append(esis, symbol.ESI)
+		sbns = append(sbns, symbol.SBN)
+		indices = append(indices, i)
+	}
+	if len(indices) == 0 {
+		return
+	}
+	errs := make([]byte, len(indices))
+	swig.AddSymbols(dec.wrapped, data, esis, sbns, errs)
+	for j, i := range indices {
+		status[i] = decodeStatus(swig.RaptorQ__v1Error(errs[j]))
+		if status[i] == raptorq.SymbolAccepted {
+			sbn := symbols[i].SBN
+			dec.counts.Add(sbn, symbols[i].ESI, dec.NumSourceSymbols(sbn))
+		}
+	}
+	return
+}
+
+func decodeStatus(e swig.RaptorQ__v1Error) raptorq.DecodeStatus {
+	switch e {
+	case swig.Error_NONE:
+		return raptorq.SymbolAccepted
+	case swig.Error_NOT_NEEDED:
+		return raptorq.SymbolNotNeeded
+	default:
+		return raptorq.SymbolRejected
+	}
+}
+
+// EndOfInput signals no more symbols will be fed for the given source block,
+// and returns which source symbols are known.
+//
+// If fillZeros is true,
+// source symbols that are not known are filled with zeros,
+// so the source block can then be retrieved using SourceBlock.
+func (dec *Decoder) EndOfInput(sbn uint8, fillZeros bool) (
+	known []bool, err error,
+) {
+	numSourceSymbols := dec.NumSourceSymbols(sbn)
+	if numSourceSymbols == 0 {
+		err = errors.New("source block number out of range")
+		return
+	}
+	bitmap := make([]byte, numSourceSymbols)
+	n := int(swig.EndOfInput(dec.wrapped, fillZeros, sbn, bitmap))
+	if n != len(bitmap) {
+		err = errors.New("libRaptorQ end-of-input failure")
+		return
+	}
+	known = make([]bool, n)
+	for i, b := range bitmap {
+		known[i] = b != 0
+	}
+	return
+}
+
+// NumReceivedSymbols returns the number of encoding symbols accepted so far
+// for the given source block.
+func (dec *Decoder) NumReceivedSymbols(sbn uint8) uint32 {
+	return dec.counts.Count(sbn)
+}
+
+// MissingSourceSymbols returns the ESIs of the source symbols not received
+// for the given source block, or none if the source block is ready.
+func (dec *Decoder) MissingSourceSymbols(sbn uint8) (
+	esis []uint32, err error,
+) {
+	numSourceSymbols := dec.NumSourceSymbols(sbn)
+	switch {
+	case numSourceSymbols == 0:
+		err = errors.New("source block number out of range")
+	case dec.IsSourceBlockReady(sbn):
+	default:
+		esis = dec.counts.Missing(sbn, numSourceSymbols)
+	}
+	return
 }
 
 // IsSourceBlockReady returns whether the given source block is ready.
@@ -165,6 +272,27 @@ func (dec *Decoder) SourceBlock(sbn uint8, buf []byte) (n int, err error) {
 	return
 }
 
+// SourceSymbol retrieves the given source symbol into the given buffer.
+//
+// The source symbol can be retrieved as soon as it has been received or
+// recovered, even if the rest of the source block is not ready.
+func (dec *Decoder) SourceSymbol(sbn uint8, esi uint32, buf []byte) (
+	n int, err error,
+) {
+	switch {
+	case esi >= uint32(dec.NumSourceSymbols(sbn)):
+		err = errors.New("source symbol out of range")
+	case len(buf) < int(dec.SymbolSize()):
+		err = errors.New("RaptorQ decoder buffer too small")
+	default:
+		n = int(dec.wrapped.Decode_symbol(buf, uint16(esi), sbn))
+		if n == 0 {
+			err = raptorq.SourceSymbolNotReady{SBN: sbn, ESI: esi}
+		}
+	}
+	return
+}
+
 // SourceObject retrieves the entire source object into the given buffer.
 func (dec *Decoder) SourceObject(buf []byte) (n int, err error) {
 	n = int(dec.wrapped.Decode_bytes(buf, 0))
@@ -174,11 +302,51 @@ func (dec *Decoder) SourceObject(buf []byte) (n int, err error) {
 	return
 }
 
+// ReadAt reads the given range of the source object into the given buffer.
+//
+// ReadAt implements io.ReaderAt.
+// It returns raptorq.SourceBlockNotReady if a source block covering the range
+// has not been decoded yet.
+func (dec *Decoder) ReadAt(p []byte, off int64) (n int, err error) {
+	return sourceobject.ReadAt(dec, dec.readBlock, p, off)
+}
+
+// readBlock reads the given range of the given source block into p, copying
+// only the requested octets out of libRaptorQ.
+func (dec *Decoder) readBlock(sbn uint8, p []byte, off int64) (
+	n int, err error,
+) {
+	n = int(swig.DecodeBlockRange(dec.wrapped, sbn, uint64(off), p))
+	if n < len(p) {
+		esi := (off + int64(n)) / int64(dec.SymbolSize())
+		err = raptorq.SourceSymbolNotReady{SBN: sbn, ESI: uint32(esi)}
+	}
+	return
+}
+
+// Reader returns a reader that streams the source object in order,
+// waiting for each source block to become ready.
+//
+// The reader frees each source block after copying it out.
+func (dec *Decoder) Reader(ctx context.Context) io.Reader {
+	return sourceobject.NewReader(ctx, dec)
+}
+
 // FreeSourceBlock frees all internal memory used for the given source block.
 func (dec *Decoder) FreeSourceBlock(sbn uint8) {
 	dec.wrapped.Free(sbn)
 }
 
+// Reencoder returns an encoder that generates encoding symbols for the source
+// blocks recovered by this decoder.
+func (dec *Decoder) Reencoder() (enc raptorq.Encoder, err error) {
+	reencoder, err := NewReencoder(dec)
+	if err == nil {
+		enc = reencoder
+	}
+	return
+}
+
 // AddReadyBlockChan adds a channel through which the decoder notifies the
 // block number of each source block ready.
 //
@@ -0,0 +1,170 @@
+package libraptorq
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/internal/rfc6330"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// Reencoder is an encoder that generates encoding symbols for the source
+// blocks recovered by a decoder.
+//
+// Each source block of an RFC 6330 object is encoded independently of others,
+// so Reencoder encodes each recovered source block as a single-block object,
+// with sender parameters chosen to reproduce the same source symbols, the same
+// sub-blocking and therefore the same encoding symbols as the original sender.
+type Reencoder struct {
+	// Reencoder needs to provide the same object information as the decoder.
+	raptorq.ObjectInfo
+
+	dec    *Decoder
+	mutex  sync.Mutex
+	blocks []raptorq.Encoder
+	closed bool
+}
+
+// NewReencoder returns a new encoder for source blocks recovered by the given
+// decoder.
+func NewReencoder(dec *Decoder) (enc *Reencoder, err error) {
+	if dec.SymbolAlignmentParameter() != 1 {
+		// libRaptorQ byte encoders always use 1-octet alignment.
+		err = errors.New("cannot re-encode objects with symbol alignment " +
+			"other than 1")
+		return
+	}
+	enc = &Reencoder{
+		ObjectInfo: dec,
+		dec:        dec,
+		blocks:     make([]raptorq.Encoder, dec.NumSourceBlocks()),
+	}
+	return
+}
+
+// blockEncoder returns the single-block encoder for the given source block,
+// creating one if the source block has been recovered.
+func (enc *Reencoder) blockEncoder(sbn uint8) (
+	blockEnc raptorq.Encoder, err error,
+) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if enc.closed {
+		panic("RaptorQ re-encoder already closed")
+	}
+	if int(sbn) >= len(enc.blocks) {
+		err = errors.New("source block number out of range")
+		return
+	}
+	if blockEnc = enc.blocks[sbn]; blockEnc != nil {
+		return
+	}
+	if !enc.dec.IsSourceBlockReady(sbn) {
+		err = raptorq.SourceBlockNotReady(sbn)
+		return
+	}
+	block := make([]byte, enc.dec.SourceBlockSize(sbn))
+	if _, err = enc.dec.SourceBlock(sbn, block); err != nil {
+		return
+	}
+	numSourceSymbols := enc.dec.NumSourceSymbols(sbn)
+	symbolSize := enc.dec.SymbolSize()
+	numSubBlocks := enc.dec.NumSubBlocks()
+	minSubSymbolSize, maxSubBlockSize, ok := rfc6330.SingleBlockParams(
+		numSourceSymbols, symbolSize, numSubBlocks, 1)
+	if !ok {
+		err = errors.New("no sender parameters reproduce the source block")
+		return
+	}
+	blockEnc, err = (&EncoderFactory{}).New(block, symbolSize,
+		minSubSymbolSize, maxSubBlockSize, 1)
+	if err != nil {
+		return
+	}
+	if blockEnc.NumSourceBlocks() != 1 ||
+		blockEnc.NumSourceSymbols(0) != numSourceSymbols ||
+		blockEnc.NumSubBlocks() != numSubBlocks {
+		_ = blockEnc.Close()
+		blockEnc = nil
+		err = errors.New("re-encoded source block does not match original")
+		return
+	}
+	enc.blocks[sbn] = blockEnc
+	return
+}
+
+// Encode writes the encoding symbol identified by the given source block
+// number – encoding symbol ID pair into the given buffer.
+//
+// Encode returns raptorq.SourceBlockNotReady if the decoder has not recovered
+// the source block yet.
+func (enc *Reencoder) Encode(sbn uint8, esi uint32, buf []byte) (
+	written uint, err error,
+) {
+	blockEnc, err := enc.blockEncoder(sbn)
+	if err != nil {
+		return
+	}
+	return blockEnc.Encode(0, esi, buf)
+}
+
+// MaxSubBlockSize returns the smallest maximum sub-block size, in octets,
+// that reproduces the first (and largest) source block.
+//
+// The original sender may have used a larger value,
+// which is not recorded in the object transmission information.
+func (enc *Reencoder) MaxSubBlockSize() uint32 {
+	_, maxSubBlockSize, _ := rfc6330.SingleBlockParams(
+		enc.NumSourceSymbols(0), enc.SymbolSize(), enc.NumSubBlocks(), 1)
+	return maxSubBlockSize
+}
+
+// FreeSourceBlock frees resource used for encoding the given source block.
+//
+// Once freed, the source block is encoded again from the decoder as needed.
+func (enc *Reencoder) FreeSourceBlock(sbn uint8) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if int(sbn) < len(enc.blocks) && enc.blocks[sbn] != nil {
+		_ = enc.blocks[sbn].Close()
+		enc.blocks[sbn] = nil
+	}
+}
+
+// MinSymbols returns the number of encoding symbols needed to recover the
+// given source block with 99% probability, or 0 if sbn is out of range.
+func (enc *Reencoder) MinSymbols(sbn uint8) uint16 {
+	k := enc.NumSourceSymbols(sbn)
+	if k == 0 {
+		return 0
+	}
+	return rfc6330.KPrime(uint32(k))
+}
+
+// MaxSymbols returns the number of encoding symbols that can be generated
+// for the given source block, or 0 if it has not been recovered yet.
+func (enc *Reencoder) MaxSymbols(sbn uint8) uint32 {
+	blockEnc, err := enc.blockEncoder(sbn)
+	if err != nil {
+		return 0
+	}
+	return blockEnc.MaxSymbols(0)
+}
+
+// Close closes the re-encoder.  The decoder is left open.
+func (enc *Reencoder) Close() (err error) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if enc.closed {
+		err = errors.New("RaptorQ re-encoder already closed")
+		return
+	}
+	for sbn, blockEnc := range enc.blocks {
+		if blockEnc != nil {
+			_ = blockEnc.Close()
+			enc.blocks[sbn] = nil
+		}
+	}
+	enc.closed = true
+	return
+}
@@ -0,0 +1,39 @@
+//go:build cgo
+// +build cgo
+
+package libraptorq
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/internal/rfc6330"
+)
+
+func TestReencoderMinSymbols(t *testing.T) {
+	input := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(input)
+	enc, err := (&EncoderFactory{}).New(input, 16, 16, 512, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	dec, err := (&DecoderFactory{}).New(enc.CommonOTI(), enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	reenc, err := dec.Reencoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reenc.Close()
+	// Not recovered yet, but the number of source symbols is known.
+	k := uint32(dec.NumSourceSymbols(0))
+	if n, want := reenc.MinSymbols(0), rfc6330.KPrime(k); n != want {
+		t.Errorf("MinSymbols(0) = %d, want %d", n, want)
+	}
+	if n := reenc.MinSymbols(dec.NumSourceBlocks()); n != 0 {
+		t.Errorf("MinSymbols out of range = %d, want 0", n)
+	}
+}
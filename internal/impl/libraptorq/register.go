@@ -0,0 +1,17 @@
+package libraptorq
+
+import "github.com/harmony-one/go-raptorq/pkg/registry"
+
+func init() {
+	registry.Register(registry.Codec{
+		Name: registry.LibRaptorQ,
+		Capabilities: registry.Capabilities{
+			FECEncodingID:    6,
+			MaxSourceSymbols: 56403,
+			MaxSymbols:       1 << 24,
+			Cgo:              true,
+		},
+		EncoderFactory: &EncoderFactory{},
+		DecoderFactory: &DecoderFactory{},
+	})
+}
@@ -0,0 +1,73 @@
+//go:build cgo
+// +build cgo
+
+package libraptorq
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// TestV1RoundTrip encodes an object of more blocks than the 8-bit source block
+// numbers of RFC 6330 can address, and decodes it from repair symbols only.
+func TestV1RoundTrip(t *testing.T) {
+	const symbolSize, blockSymbols = 4, 10
+	input := make([]byte, 300*blockSymbols*symbolSize+17)
+	rand.New(rand.NewSource(1)).Read(input)
+	enc, err := (&V1EncoderFactory{}).New(input, symbolSize, blockSymbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	metadata := enc.Metadata()
+	if n := metadata.NumBlocks(); n != 301 {
+		t.Fatalf("%d blocks, want 301", n)
+	}
+	data, err := metadata.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata.TransferLength = 0
+	if err = metadata.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	dec, err := (&V1DecoderFactory{}).New(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	buf := make([]byte, symbolSize)
+	for block := uint32(0); uint64(block) < metadata.NumBlocks(); block++ {
+		for esi := uint32(blockSymbols); !dec.IsBlockReady(block); esi++ {
+			if esi == 2*blockSymbols+10 {
+				t.Fatalf("block %d not recovered from %d repair symbols",
+					block, esi-blockSymbols)
+			}
+			if _, err := enc.Encode(block, esi, buf); err != nil {
+				t.Fatal(err)
+			}
+			dec.Decode(block, esi, buf)
+		}
+		enc.FreeBlock(block)
+	}
+	if !dec.IsObjectReady() {
+		t.Fatal("object not ready")
+	}
+	output := make([]byte, len(input))
+	if _, err := dec.Object(output); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, input) {
+		t.Fatal("recovered object differs")
+	}
+
+	// Freed blocks still count as recovered, but can no longer be read.
+	dec.FreeBlock(300)
+	if !dec.IsBlockReady(300) || !dec.IsObjectReady() {
+		t.Error("freed block no longer ready")
+	}
+	if _, err := dec.Block(300, output); err == nil {
+		t.Error("Block of freed block succeeded")
+	}
+}
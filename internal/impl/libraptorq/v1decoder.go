@@ -0,0 +1,185 @@
+package libraptorq
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/internal/impl/libraptorq/swig"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// V1DecoderFactory is a factory of decoders of the RaptorQ__v1 API of
+// libRaptorQ.
+type V1DecoderFactory struct {
+}
+
+// New returns a new decoder instance for the object of the given metadata.
+func (*V1DecoderFactory) New(metadata raptorq.V1Metadata) (
+	dec raptorq.V1Decoder, err error,
+) {
+	if err = metadata.Validate(); err != nil {
+		return
+	}
+	dec = &V1Decoder{
+		metadata: metadata,
+		decoders: make(map[uint32]swig.BlockDecoder),
+		counts:   make(map[uint32]uint32),
+		blocks:   make(map[uint32][]byte),
+	}
+	return
+}
+
+// V1Decoder is a decoder of the RaptorQ__v1 API of libRaptorQ.
+//
+// V1Decoder creates the libRaptorQ decoder of each block when its first
+// symbol arrives, and decodes synchronously once it has received as many
+// symbols as the block has source symbols.  Recovered blocks are copied out of
+// libRaptorQ, whose decoder is then deleted.
+type V1Decoder struct {
+	metadata raptorq.V1Metadata
+	mutex    sync.Mutex
+	decoders map[uint32]swig.BlockDecoder
+	counts   map[uint32]uint32
+	blocks   map[uint32][]byte // recovered blocks; nil once freed
+	closed   bool
+}
+
+// Metadata returns the metadata of the object.
+func (dec *V1Decoder) Metadata() raptorq.V1Metadata {
+	return dec.metadata
+}
+
+// checkOpen panics if the decoder has been closed.
+//
+// The caller must hold the mutex.
+func (dec *V1Decoder) checkOpen() {
+	if dec.closed {
+		panic("RaptorQ decoder already closed")
+	}
+}
+
+// Decode decodes the given encoding symbol.
+func (dec *V1Decoder) Decode(block uint32, esi uint32, symbol []byte) (
+	status raptorq.DecodeStatus,
+) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	dec.checkOpen()
+	if dec.metadata.SourceBlockSize(block) == 0 ||
+		len(symbol) != int(dec.metadata.SymbolSize) {
+		return raptorq.SymbolRejected
+	}
+	if _, ok := dec.blocks[block]; ok {
+		return raptorq.SymbolNotNeeded
+	}
+	wrapped := dec.decoders[block]
+	if wrapped == nil {
+		wrapped = swig.NewBlockDecoder(dec.metadata.BlockSymbols,
+			dec.metadata.SymbolSize)
+		if !wrapped.Initialized() {
+			swig.DeleteBlockDecoder(wrapped)
+			return raptorq.SymbolRejected
+		}
+		dec.decoders[block] = wrapped
+	}
+	status = decodeStatus(swig.RaptorQ__v1Error(wrapped.AddSymbol(symbol, esi)))
+	if status != raptorq.SymbolAccepted {
+		return
+	}
+	dec.counts[block]++
+	if dec.counts[block] < uint32(dec.metadata.BlockSymbols) {
+		return
+	}
+	buf := make([]byte, dec.metadata.BlockSize())
+	if wrapped.Decode(buf) {
+		dec.blocks[block] = buf[:dec.metadata.SourceBlockSize(block)]
+		swig.DeleteBlockDecoder(wrapped)
+		delete(dec.decoders, block)
+		delete(dec.counts, block)
+	}
+	return
+}
+
+// IsBlockReady returns whether the given block has been recovered, including
+// since freed with FreeBlock.
+func (dec *V1Decoder) IsBlockReady(block uint32) bool {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	_, ok := dec.blocks[block]
+	return ok
+}
+
+// IsObjectReady returns whether all blocks have been recovered.
+func (dec *V1Decoder) IsObjectReady() bool {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	return uint64(len(dec.blocks)) == dec.metadata.NumBlocks()
+}
+
+// Block copies the object data of the given block into buf.
+func (dec *V1Decoder) Block(block uint32, buf []byte) (n int, err error) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	dec.checkOpen()
+	data, ok := dec.blocks[block]
+	switch {
+	case dec.metadata.SourceBlockSize(block) == 0:
+		err = errors.New("block number out of range")
+	case !ok:
+		err = raptorq.BlockNotReady(block)
+	case data == nil:
+		err = errors.New("block already freed")
+	case len(buf) < len(data):
+		err = errors.New("RaptorQ decoder buffer too small")
+	default:
+		n = copy(buf, data)
+	}
+	return
+}
+
+// Object copies the object into buf.
+func (dec *V1Decoder) Object(buf []byte) (n int, err error) {
+	if uint64(len(buf)) < dec.metadata.TransferLength {
+		err = errors.New("RaptorQ decoder buffer too small")
+		return
+	}
+	for block := uint64(0); block < dec.metadata.NumBlocks(); block++ {
+		var m int
+		if m, err = dec.Block(uint32(block), buf[n:]); err != nil {
+			return
+		}
+		n += m
+	}
+	return
+}
+
+// FreeBlock frees the memory used for the given block.
+func (dec *V1Decoder) FreeBlock(block uint32) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	if wrapped, ok := dec.decoders[block]; ok {
+		swig.DeleteBlockDecoder(wrapped)
+		delete(dec.decoders, block)
+		delete(dec.counts, block)
+	}
+	if _, ok := dec.blocks[block]; ok {
+		dec.blocks[block] = nil
+	}
+}
+
+// Close closes the decoder.
+func (dec *V1Decoder) Close() (err error) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	if dec.closed {
+		err = errors.New("RaptorQ decoder already closed")
+		return
+	}
+	for _, wrapped := range dec.decoders {
+		swig.DeleteBlockDecoder(wrapped)
+	}
+	dec.decoders = nil
+	dec.blocks = nil
+	dec.closed = true
+	return
+}
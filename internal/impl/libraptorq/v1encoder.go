@@ -0,0 +1,141 @@
+package libraptorq
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/internal/impl/libraptorq/swig"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// V1EncoderFactory is a factory of encoders of the RaptorQ__v1 API of
+// libRaptorQ.
+type V1EncoderFactory struct {
+}
+
+// New returns a new encoder instance.
+func (*V1EncoderFactory) New(input []byte, symbolSize uint16,
+	blockSymbols uint16) (enc raptorq.V1Encoder, err error) {
+	metadata := raptorq.V1Metadata{
+		TransferLength: uint64(len(input)),
+		SymbolSize:     symbolSize,
+		BlockSymbols:   blockSymbols,
+	}
+	if err = metadata.Validate(); err != nil {
+		return
+	}
+	enc = &V1Encoder{
+		metadata: metadata,
+		input:    input,
+		blocks:   make(map[uint32]swig.BlockEncoder),
+	}
+	return
+}
+
+// V1Encoder is an encoder of the RaptorQ__v1 API of libRaptorQ.
+//
+// V1Encoder creates the libRaptorQ encoder of each block when first needed,
+// and keeps it until FreeBlock, so senders of large objects should free each
+// block once done with it.
+type V1Encoder struct {
+	metadata raptorq.V1Metadata
+	input    []byte
+	mutex    sync.Mutex
+	blocks   map[uint32]swig.BlockEncoder
+	closed   bool
+}
+
+// Metadata returns the metadata of the object.
+func (enc *V1Encoder) Metadata() raptorq.V1Metadata {
+	return enc.metadata
+}
+
+// blockEncoder returns the libRaptorQ encoder of the given block, creating
+// one if needed.
+func (enc *V1Encoder) blockEncoder(block uint32) (
+	wrapped swig.BlockEncoder, err error,
+) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if enc.closed {
+		panic("RaptorQ encoder already closed")
+	}
+	if wrapped = enc.blocks[block]; wrapped != nil {
+		return
+	}
+	size := uint64(enc.metadata.SourceBlockSize(block))
+	if size == 0 {
+		err = errors.New("block number out of range")
+		return
+	}
+	start := uint64(block) * enc.metadata.BlockSize()
+	wrapped = swig.NewBlockEncoder(enc.input[start:start+size],
+		enc.metadata.BlockSymbols, enc.metadata.SymbolSize)
+	if !wrapped.Initialized() {
+		swig.DeleteBlockEncoder(wrapped)
+		wrapped = nil
+		err = errors.New("libRaptorQ block encoder failed to initialize")
+		return
+	}
+	enc.blocks[block] = wrapped
+	return
+}
+
+// Encode writes the encoding symbol identified by the given block number and
+// encoding symbol ID into buf.
+func (enc *V1Encoder) Encode(block uint32, esi uint32, buf []byte) (
+	written uint, err error,
+) {
+	if len(buf) < int(enc.metadata.SymbolSize) {
+		err = errors.New("RaptorQ encoder buffer too small")
+		return
+	}
+	wrapped, err := enc.blockEncoder(block)
+	if err != nil {
+		return
+	}
+	written = uint(wrapped.Encode(buf, esi))
+	if written == 0 {
+		err = errors.New("RaptorQ encoder returned an error indication")
+	}
+	return
+}
+
+// MaxSymbols returns the number of encoding symbols that can be generated for
+// the given block, or 0 if block is out of range.
+//
+// MaxSymbols creates the libRaptorQ encoder of the block if needed.
+func (enc *V1Encoder) MaxSymbols(block uint32) uint32 {
+	wrapped, err := enc.blockEncoder(block)
+	if err != nil {
+		return 0
+	}
+	return uint32(enc.metadata.BlockSymbols) + wrapped.MaxRepair()
+}
+
+// FreeBlock frees the libRaptorQ encoder of the given block.
+func (enc *V1Encoder) FreeBlock(block uint32) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if wrapped, ok := enc.blocks[block]; ok {
+		swig.DeleteBlockEncoder(wrapped)
+		delete(enc.blocks, block)
+	}
+}
+
+// Close closes the encoder.
+func (enc *V1Encoder) Close() (err error) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if enc.closed {
+		err = errors.New("RaptorQ encoder already closed")
+		return
+	}
+	for _, wrapped := range enc.blocks {
+		swig.DeleteBlockEncoder(wrapped)
+	}
+	enc.blocks = nil
+	enc.input = nil
+	enc.closed = true
+	return
+}
@@ -0,0 +1,148 @@
+package r10
+
+import (
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/internal/blockcodec"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// MaxTransferLength is the largest source object size, in octets, that the
+// common OTI can convey.
+const MaxTransferLength = 1<<40 - 1
+
+// checkLayout checks that every source block of the given layout has a number
+// of source symbols that R10 supports.
+func checkLayout(l *blockcodec.Layout) error {
+	for sbn := 0; sbn < int(l.NumSourceBlocks()); sbn++ {
+		k := l.NumSourceSymbols(uint8(sbn))
+		if k < MinSourceSymbols || k > MaxSourceSymbols {
+			return errors.New("number of R10 source symbols out of range")
+		}
+	}
+	return nil
+}
+
+// errNoTables is returned by the factories while the RFC 5053 tables are not
+// available.
+var errNoTables = errors.New("R10 tables of RFC 5053 not available")
+
+// EncoderFactory is a factory of R10 encoder instances.
+type EncoderFactory struct {
+}
+
+// New returns a new encoder instance.
+//
+// New partitions the source object as derived in RFC 5053 Section 4.2; see
+// partition.
+//
+// New returns an error if the source object does not fit into the OTI, or
+// has fewer than MinSourceSymbols source symbols.
+func (*EncoderFactory) New(input []byte, symbolSize uint16,
+	minSubSymbolSize uint16, maxSubBlockSize uint32, alignment uint8,
+) (enc raptorq.Encoder, err error) {
+	if !tablesAvailable() {
+		err = errNoTables
+		return
+	}
+	switch {
+	case len(input) == 0 || uint64(len(input)) > MaxTransferLength:
+		err = errors.New("source object size out of range")
+	case alignment == 0 || symbolSize%uint16(alignment) != 0 ||
+		minSubSymbolSize%uint16(alignment) != 0:
+		err = errors.New("symbol sizes must be multiples of alignment")
+	case minSubSymbolSize == 0 || minSubSymbolSize > symbolSize ||
+		maxSubBlockSize == 0:
+		err = errors.New("invalid sub-block parameters")
+	}
+	if err != nil {
+		return
+	}
+	transferLength := uint64(len(input))
+	z, n, err := partition(transferLength, symbolSize, minSubSymbolSize,
+		maxSubBlockSize)
+	if err != nil {
+		return
+	}
+	layout, err := blockcodec.NewLayout(transferLength, symbolSize, z, n,
+		alignment, commonOTI(transferLength, symbolSize),
+		schemeSpecificOTI(z, n, alignment))
+	if err != nil {
+		return
+	}
+	if err = checkLayout(layout); err != nil {
+		return
+	}
+	enc = blockcodec.NewEncoder(scheme{}, layout, input, maxSubBlockSize)
+	return
+}
+
+// partition returns the number of source blocks Z and of sub-blocks N of a
+// source object of the given transfer length F, as derived in RFC 5053
+// Section 4.2:
+//
+//	Kt = ceil(F/T)
+//	Z  = ceil(Kt/KMax)
+//	N  = min{ceil(ceil(Kt/Z)*T/W), T/SS}
+//
+// where KMax is MaxSourceSymbols, W the maximum sub-block size, and SS the
+// minimum sub-symbol size, which stands for the symbol alignment A of the RFC
+// and equals it when callers have no other minimum.
+//
+// partition returns an error if Z exceeds the 255 source blocks that the
+// raptorq interfaces can address.
+func partition(
+	transferLength uint64, symbolSize uint16, minSubSymbolSize uint16,
+	maxSubBlockSize uint32,
+) (z uint8, n uint16, err error) {
+	t := uint64(symbolSize)
+	kt := (transferLength + t - 1) / t
+	numBlocks := (kt + MaxSourceSymbols - 1) / MaxSourceSymbols
+	if numBlocks > 255 {
+		err = errors.New("source object too large for 255 R10 source blocks")
+		return
+	}
+	kl := (kt + numBlocks - 1) / numBlocks
+	numSubBlocks := (kl*t + uint64(maxSubBlockSize) - 1) /
+		uint64(maxSubBlockSize)
+	if maxN := t / uint64(minSubSymbolSize); numSubBlocks > maxN {
+		numSubBlocks = maxN
+	}
+	if numSubBlocks > 255 {
+		// N is 8 bits long in the scheme-specific OTI.
+		numSubBlocks = 255
+	}
+	z, n = uint8(numBlocks), uint16(numSubBlocks)
+	return
+}
+
+// DecoderFactory is a factory of R10 decoder instances.
+type DecoderFactory struct {
+}
+
+// New returns a new decoder instance for the given R10 OTIs.
+//
+// New returns an error if the OTIs are malformed or out of range.
+func (*DecoderFactory) New(commonOTI uint64, schemeSpecificOTI uint32) (
+	dec raptorq.Decoder, err error,
+) {
+	if !tablesAvailable() {
+		err = errNoTables
+		return
+	}
+	if commonOTI>>56 != 0 || schemeSpecificOTI>>24 != 0 {
+		err = errors.New("R10 OTI out of range")
+		return
+	}
+	layout, err := blockcodec.NewLayout(commonOTI>>24, uint16(commonOTI),
+		uint8(schemeSpecificOTI>>16), uint16(uint8(schemeSpecificOTI>>8)),
+		uint8(schemeSpecificOTI), commonOTI, schemeSpecificOTI)
+	if err != nil {
+		return
+	}
+	if err = checkLayout(layout); err != nil {
+		return
+	}
+	dec, err = blockcodec.NewDecoder(scheme{}, layout)
+	return
+}
@@ -0,0 +1,115 @@
+package r10
+
+// degrees is the degree generator of RFC 5053 Section 5.4.4.2: Deg[v] is
+// degrees[j].d for the first j such that v < degrees[j].f.
+var degrees = []struct{ f, d int }{
+	{10241, 1}, {491582, 2}, {712794, 3}, {831695, 4},
+	{948446, 10}, {1032189, 11}, {1048576, 40},
+}
+
+// random is the random number generator Rand[X, i, m] of RFC 5053 Section
+// 5.4.4.1.
+func random(x, i, m uint32) uint32 {
+	return (v0[(x+i)%256] ^ v1[(x/256+i)%256]) % m
+}
+
+func degree(v uint32) int {
+	for _, e := range degrees {
+		if int(v) < e.f {
+			return e.d
+		}
+	}
+	return degrees[len(degrees)-1].d
+}
+
+// triple is the triple generator Trip[K, X] of RFC 5053 Section 5.4.4.4.
+func (p *params) triple(x uint32, j int) (d int, a, b uint32) {
+	const q = 65521
+	aa := (53591 + uint32(j)*997) % q
+	bb := 10267 * (uint32(j) + 1) % q
+	y := (bb + x*aa) % q
+	d = degree(random(y, 0, 1<<20))
+	a = 1 + random(y, 1, uint32(p.lp-1))
+	b = random(y, 2, uint32(p.lp))
+	return
+}
+
+// ltIndices returns the intermediate symbols XORed into the encoding symbol of
+// the given ESI by LTEnc of RFC 5053 Section 5.4.4.3, given J(K).
+func (p *params) ltIndices(esi uint32, j int) (indices []int) {
+	d, a, b := p.triple(esi, j)
+	l, lp := uint32(p.l), uint32(p.lp)
+	for b >= l {
+		b = (b + a) % lp
+	}
+	indices = append(indices, int(b))
+	if d > p.l {
+		d = p.l
+	}
+	for n := 1; n < d; n++ {
+		b = (b + a) % lp
+		for b >= l {
+			b = (b + a) % lp
+		}
+		indices = append(indices, int(b))
+	}
+	return
+}
+
+// constraintRows returns the S LDPC rows and the H Half rows of the constraint
+// matrix of RFC 5053 Section 5.4.2.4, each a bit set over the L intermediate
+// symbols whose XOR is zero.
+func (p *params) constraintRows() (rows []bitSet) {
+	rows = make([]bitSet, p.s+p.h)
+	for i := range rows {
+		rows[i] = newBitSet(p.l)
+	}
+	// LDPC symbols, Section 5.4.2.3.
+	for i := 0; i < p.k; i++ {
+		a := 1 + i/p.s%(p.s-1)
+		b := i % p.s
+		for n := 0; n < 3; n++ {
+			rows[b].flip(i)
+			b = (b + a) % p.s
+		}
+	}
+	for b := 0; b < p.s; b++ {
+		rows[b].flip(p.k + b)
+	}
+	// Half symbols: bit h of the j-th Gray code of weight H' = ceil(H/2)
+	// tells whether intermediate symbol j is XORed into Half symbol h.
+	hp := (p.h + 1) / 2
+	for i, j := 0, 0; j < p.k+p.s; i++ {
+		g := i ^ i>>1
+		if popCount(g) != hp {
+			continue
+		}
+		for h := 0; h < p.h; h++ {
+			if g>>uint(h)&1 != 0 {
+				rows[p.s+h].flip(j)
+			}
+		}
+		j++
+	}
+	for h := 0; h < p.h; h++ {
+		rows[p.s+h].flip(p.k + p.s + h)
+	}
+	return
+}
+
+// ltRow returns the row of the constraint matrix for the encoding symbol of the
+// given ESI.
+func (p *params) ltRow(esi uint32, j int) (row bitSet) {
+	row = newBitSet(p.l)
+	for _, i := range p.ltIndices(esi, j) {
+		row.flip(i)
+	}
+	return
+}
+
+func popCount(v int) (n int) {
+	for ; v != 0; v &= v - 1 {
+		n++
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package r10
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The OTIs of R10 objects carry the parameters of RFC 5053 Section 3.2.3 in
+// the integers of the raptorq interfaces, as follows:
+//
+//	common OTI:          F (40 bits) || T (16 bits) in the low 56 bits
+//	scheme-specific OTI: Z (16 bits) || N (8 bits) || Al (8 bits)
+//
+// The common OTI thus differs from the 80-bit encoding of RFC 5053, whose
+// 48-bit transfer length and 16 reserved bits do not fit into 64 bits;
+// PutCommonOTI and ParseCommonOTI convert between the two.  Z never exceeds
+// 255, since source block numbers are 8-bit in the raptorq interfaces.
+
+// Sizes of the encoded FEC OTI elements of RFC 5053 Section 3.2.3, in octets.
+const (
+	EncodedCommonOTISize         = 10
+	EncodedSchemeSpecificOTISize = 4
+)
+
+func commonOTI(transferLength uint64, symbolSize uint16) uint64 {
+	return transferLength<<24 | uint64(symbolSize)
+}
+
+func schemeSpecificOTI(z uint8, n uint16, al uint8) uint32 {
+	return uint32(z)<<16 | uint32(n)<<8 | uint32(al)
+}
+
+// PutCommonOTI writes the given common OTI into b in the 80-bit encoding of
+// RFC 5053 Section 3.2.3:
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                       Transfer Length                         |
+//	+                               +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                               |           Reserved            |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|    Encoding Symbol Length     |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+// b must have at least EncodedCommonOTISize octets.
+func PutCommonOTI(b []byte, commonOTI uint64) {
+	transferLength := commonOTI >> 24
+	binary.BigEndian.PutUint16(b, uint16(transferLength>>32))
+	binary.BigEndian.PutUint32(b[2:], uint32(transferLength))
+	binary.BigEndian.PutUint16(b[6:], 0)
+	binary.BigEndian.PutUint16(b[8:], uint16(commonOTI))
+}
+
+// ParseCommonOTI parses the 80-bit common OTI at the beginning of b, ignoring
+// the reserved field.
+//
+// ParseCommonOTI returns an error for transfer lengths beyond
+// MaxTransferLength, which the common OTI of the raptorq interfaces cannot
+// convey.
+func ParseCommonOTI(b []byte) (commonOTI uint64, err error) {
+	if len(b) < EncodedCommonOTISize {
+		err = errors.New("common FEC OTI too short")
+		return
+	}
+	transferLength := uint64(binary.BigEndian.Uint16(b))<<32 |
+		uint64(binary.BigEndian.Uint32(b[2:]))
+	if transferLength > MaxTransferLength {
+		err = errors.New("transfer length out of range")
+		return
+	}
+	commonOTI = transferLength<<24 | uint64(binary.BigEndian.Uint16(b[8:]))
+	return
+}
+
+// PutSchemeSpecificOTI writes the given scheme-specific OTI into b in the
+// encoding of RFC 5053 Section 3.2.3:
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|             Z                 |      N        |       Al      |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+// which the scheme-specific OTI of the raptorq interfaces shares.  b must
+// have at least EncodedSchemeSpecificOTISize octets.
+func PutSchemeSpecificOTI(b []byte, schemeSpecificOTI uint32) {
+	binary.BigEndian.PutUint32(b, schemeSpecificOTI)
+}
+
+// ParseSchemeSpecificOTI parses the scheme-specific OTI at the beginning of b.
+//
+// ParseSchemeSpecificOTI returns an error for numbers of source blocks beyond
+// 255, which the raptorq interfaces cannot address.
+func ParseSchemeSpecificOTI(b []byte) (schemeSpecificOTI uint32, err error) {
+	if len(b) < EncodedSchemeSpecificOTISize {
+		err = errors.New("scheme-specific FEC OTI too short")
+		return
+	}
+	if b[0] != 0 {
+		err = errors.New("number of source blocks out of range")
+		return
+	}
+	schemeSpecificOTI = binary.BigEndian.Uint32(b)
+	return
+}
@@ -0,0 +1,75 @@
+// Package r10 implements the Raptor code of RFC 5053 (R10, FEC Encoding ID 1)
+// behind raptorq.Encoder and raptorq.Decoder, for interoperating with peers
+// that predate RaptorQ.
+//
+// The random number tables and systematic indices of RFC 5053 are not
+// vendored yet.  Until they are, the factories fail, and the codec does not
+// register itself.
+package r10
+
+// MinSourceSymbols and MaxSourceSymbols bound the number of source symbols per
+// source block, “K” in RFC 5053.
+const (
+	MinSourceSymbols = 4
+	MaxSourceSymbols = 8192
+)
+
+// MaxSymbols is the number of encoding symbols per source block that the 16-bit
+// ESI of the R10 FEC Payload ID can identify.
+const MaxSymbols = 1 << 16
+
+// params are the code parameters of RFC 5053 Section 5.4.2.3 for a source block
+// of k source symbols.
+type params struct {
+	k  int // number of source symbols
+	s  int // number of LDPC symbols
+	h  int // number of Half symbols
+	l  int // number of intermediate symbols, K+S+H
+	lp int // smallest prime not less than L, “L'”
+}
+
+func newParams(k int) (p params) {
+	x := 1
+	for x*(x-1) < 2*k {
+		x++
+	}
+	p.k = k
+	p.s = nextPrime((k+99)/100 + x)
+	p.h = 1
+	for choose(p.h, (p.h+1)/2) < k+p.s {
+		p.h++
+	}
+	p.l = k + p.s + p.h
+	p.lp = nextPrime(p.l)
+	return
+}
+
+// nextPrime returns the smallest prime not less than n.
+func nextPrime(n int) int {
+	for ; ; n++ {
+		if isPrime(n) {
+			return n
+		}
+	}
+}
+
+func isPrime(n int) bool {
+	if n < 2 {
+		return false
+	}
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// choose returns the binomial coefficient C(n, k).
+func choose(n, k int) int {
+	c := 1
+	for i := 1; i <= k; i++ {
+		c = c * (n - k + i) / i
+	}
+	return c
+}
@@ -0,0 +1,36 @@
+package r10
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// FECPayloadIDSize is the size of the R10 FEC Payload ID, in octets.
+const FECPayloadIDSize = 4
+
+// PutFECPayloadID writes the FEC Payload ID for the given source block number
+// and encoding symbol ID into b, as specified in RFC 5053 Section 3.2.2: a
+// 16-bit source block number followed by a 16-bit encoding symbol ID.  b must
+// have at least FECPayloadIDSize octets.
+func PutFECPayloadID(b []byte, sbn uint8, esi uint16) {
+	binary.BigEndian.PutUint16(b, uint16(sbn))
+	binary.BigEndian.PutUint16(b[2:], esi)
+}
+
+// ParseFECPayloadID parses the FEC Payload ID at the beginning of b.
+//
+// ParseFECPayloadID returns an error for source block numbers beyond 255,
+// which the raptorq interfaces cannot address.
+func ParseFECPayloadID(b []byte) (sbn uint8, esi uint16, err error) {
+	if len(b) < FECPayloadIDSize {
+		err = errors.New("FEC Payload ID too short")
+		return
+	}
+	if b[0] != 0 {
+		err = errors.New("source block number out of range")
+		return
+	}
+	sbn = b[1]
+	esi = binary.BigEndian.Uint16(b[2:])
+	return
+}
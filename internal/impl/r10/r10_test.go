@@ -0,0 +1,196 @@
+package r10
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+// requireTables skips the test if the RFC 5053 tables are not vendored, as
+// symbols computed from anything else would not match other implementations.
+func requireTables(t *testing.T) {
+	if !tablesAvailable() {
+		t.Skip("RFC 5053 tables not vendored")
+	}
+}
+
+func TestNoTables(t *testing.T) {
+	if tablesAvailable() {
+		t.Skip("RFC 5053 tables vendored")
+	}
+	_, err := (&EncoderFactory{}).New(make([]byte, 64), 16, 16, 1<<20, 1)
+	if err != errNoTables {
+		t.Errorf("encoder factory: err = %v, want %v", err, errNoTables)
+	}
+	_, err = (&DecoderFactory{}).New(64<<24|16, 1<<16|1<<8|1)
+	if err != errNoTables {
+		t.Errorf("decoder factory: err = %v, want %v", err, errNoTables)
+	}
+	if _, ok := registry.Lookup(registry.R10); ok {
+		t.Error("R10 registered without its tables")
+	}
+}
+
+func TestParams(t *testing.T) {
+	p := newParams(4)
+	if p.s != 5 || p.h != 5 || p.l != 14 || p.lp != 17 {
+		t.Errorf("newParams(4) = %+v, want S=5 H=5 L=14 L'=17", p)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	requireTables(t)
+	const symbolSize = 16
+	input := make([]byte, 50*symbolSize-5)
+	rand.New(rand.NewSource(1)).Read(input)
+	// Two sub-blocks of 8-octet sub-symbols.
+	enc, err := (&EncoderFactory{}).New(input, symbolSize, 8,
+		25*symbolSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	if enc.NumSourceBlocks() != 1 || enc.NumSubBlocks() != 2 ||
+		enc.NumSourceSymbols(0) != 50 {
+		t.Fatalf("unexpected partitioning: Z=%d N=%d K=%d",
+			enc.NumSourceBlocks(), enc.NumSubBlocks(), enc.NumSourceSymbols(0))
+	}
+	dec, err := (&DecoderFactory{}).New(enc.CommonOTI(), enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	buf := make([]byte, symbolSize)
+	// Lose the first 10 source symbols, and make up with repair symbols.
+	for esi := uint32(10); !dec.IsSourceBlockReady(0); esi++ {
+		if esi == 100 {
+			t.Fatal("source block not recovered after 90 symbols")
+		}
+		if _, err := enc.Encode(0, esi, buf); err != nil {
+			t.Fatal(err)
+		}
+		dec.Decode(0, esi, buf)
+	}
+	output := make([]byte, len(input))
+	if _, err := dec.SourceObject(output); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, input) {
+		t.Fatal("recovered source object differs")
+	}
+	reenc, err := dec.Reencoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reenc.Close()
+	want := make([]byte, symbolSize)
+	for _, esi := range []uint32{3, 1000} {
+		if _, err := enc.Encode(0, esi, want); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := reenc.Encode(0, esi, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, want) {
+			t.Errorf("re-encoded symbol %d differs", esi)
+		}
+	}
+}
+
+func TestDuplicateSymbol(t *testing.T) {
+	requireTables(t)
+	input := make([]byte, 8*4)
+	enc, err := (&EncoderFactory{}).New(input, 4, 4, 1<<20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	dec, err := (&DecoderFactory{}).New(enc.CommonOTI(), enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	symbol := raptorq.Symbol{SBN: 0, ESI: 9, Data: make([]byte, 4)}
+	status := dec.DecodeBatch([]raptorq.Symbol{symbol, symbol})
+	if status[0] != raptorq.SymbolAccepted || status[1] != raptorq.SymbolNotNeeded {
+		t.Errorf("status = %v, want [accepted not needed]", status)
+	}
+}
+
+func TestFECPayloadID(t *testing.T) {
+	b := make([]byte, FECPayloadIDSize)
+	PutFECPayloadID(b, 7, 0xBEEF)
+	if !bytes.Equal(b, []byte{0, 7, 0xBE, 0xEF}) {
+		t.Errorf("FEC Payload ID = % x", b)
+	}
+	sbn, esi, err := ParseFECPayloadID(b)
+	if err != nil || sbn != 7 || esi != 0xBEEF {
+		t.Errorf("ParseFECPayloadID = %d, %d, %v", sbn, esi, err)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	for _, c := range []struct {
+		f        uint64
+		t, ss    uint16
+		w        uint32
+		wantZ    uint8
+		wantN    uint16
+		wantFail bool
+	}{
+		{f: 50*16 - 5, t: 16, ss: 8, w: 25 * 16, wantZ: 1, wantN: 2},
+		{f: 8193 * 4, t: 4, ss: 4, w: 1 << 20, wantZ: 2, wantN: 1},
+		// N is capped at T/SS, even though sub-blocks exceed W.
+		{f: 1000 * 16, t: 16, ss: 8, w: 16, wantZ: 1, wantN: 2},
+		{f: 255 * MaxSourceSymbols, t: 1, ss: 1, w: 1, wantZ: 255, wantN: 1},
+		{f: 255*MaxSourceSymbols + 1, t: 1, ss: 1, w: 1, wantFail: true},
+	} {
+		z, n, err := partition(c.f, c.t, c.ss, c.w)
+		if c.wantFail {
+			if err == nil {
+				t.Errorf("partition(%d, %d, %d, %d) = %d, %d, want error",
+					c.f, c.t, c.ss, c.w, z, n)
+			}
+			continue
+		}
+		if err != nil || z != c.wantZ || n != c.wantN {
+			t.Errorf("partition(%d, %d, %d, %d) = %d, %d, %v, want %d, %d",
+				c.f, c.t, c.ss, c.w, z, n, err, c.wantZ, c.wantN)
+		}
+	}
+}
+
+func TestOTI(t *testing.T) {
+	common := commonOTI(MaxTransferLength, 0x1234)
+	b := make([]byte, EncodedCommonOTISize)
+	PutCommonOTI(b, common)
+	want := []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0x12, 0x34}
+	if !bytes.Equal(b, want) {
+		t.Errorf("common FEC OTI = % x, want % x", b, want)
+	}
+	if got, err := ParseCommonOTI(b); err != nil || got != common {
+		t.Errorf("ParseCommonOTI = %#x, %v, want %#x", got, err, common)
+	}
+	b[0] = 1
+	if _, err := ParseCommonOTI(b); err == nil {
+		t.Error("transfer length beyond 40 bits accepted")
+	}
+
+	ss := schemeSpecificOTI(3, 2, 4)
+	b = make([]byte, EncodedSchemeSpecificOTISize)
+	PutSchemeSpecificOTI(b, ss)
+	want = []byte{0, 3, 2, 4}
+	if !bytes.Equal(b, want) {
+		t.Errorf("scheme-specific FEC OTI = % x, want % x", b, want)
+	}
+	if got, err := ParseSchemeSpecificOTI(b); err != nil || got != ss {
+		t.Errorf("ParseSchemeSpecificOTI = %#x, %v, want %#x", got, err, ss)
+	}
+	b[0] = 1
+	if _, err := ParseSchemeSpecificOTI(b); err == nil {
+		t.Error("more than 255 source blocks accepted")
+	}
+}
@@ -0,0 +1,20 @@
+package r10
+
+import "github.com/harmony-one/go-raptorq/pkg/registry"
+
+func init() {
+	// Only a usable codec is worth picking.
+	if !tablesAvailable() {
+		return
+	}
+	registry.Register(registry.Codec{
+		Name: registry.R10,
+		Capabilities: registry.Capabilities{
+			FECEncodingID:    1,
+			MaxSourceSymbols: MaxSourceSymbols,
+			MaxSymbols:       MaxSymbols,
+		},
+		EncoderFactory: &EncoderFactory{},
+		DecoderFactory: &DecoderFactory{},
+	})
+}
@@ -0,0 +1,133 @@
+package r10
+
+import (
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/internal/blockcodec"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// scheme is the R10 code as a blockcodec.Scheme.
+type scheme struct{}
+
+func (scheme) NewBlockEncoder(source [][]byte) (
+	enc blockcodec.BlockEncoder, err error,
+) {
+	p := newParams(len(source))
+	j := systematicIndex(p.k)
+	rows := p.constraintRows()
+	symbols := make([][]byte, 0, p.l)
+	symbolSize := len(source[0])
+	for range rows {
+		symbols = append(symbols, make([]byte, symbolSize))
+	}
+	for esi, symbol := range source {
+		rows = append(rows, p.ltRow(uint32(esi), j))
+		symbols = append(symbols, append([]byte(nil), symbol...))
+	}
+	intermediate := solve(rows, symbols, p.l)
+	if intermediate == nil {
+		err = errors.New("R10 systematic index yields a singular matrix")
+		return
+	}
+	enc = &blockEncoder{params: p, j: j, intermediate: intermediate}
+	return
+}
+
+func (scheme) NewBlockDecoder(numSourceSymbols uint16, symbolSize uint16) (
+	dec blockcodec.BlockDecoder, err error,
+) {
+	p := newParams(int(numSourceSymbols))
+	dec = &blockDecoder{
+		params:  p,
+		j:       systematicIndex(p.k),
+		symbols: make(map[uint32][]byte),
+	}
+	return
+}
+
+// MinSymbols returns K; R10 decoding fails with K encoding symbols about 1%
+// of the time, in which case the decoder needs a few more.
+func (scheme) MinSymbols(numSourceSymbols uint16) uint16 {
+	return numSourceSymbols
+}
+
+func (scheme) MaxSymbols(numSourceSymbols uint16) uint32 {
+	return MaxSymbols
+}
+
+// blockEncoder generates encoding symbols from the intermediate symbols of a
+// source block.
+type blockEncoder struct {
+	params
+	j            int
+	intermediate [][]byte
+}
+
+func (enc *blockEncoder) Encode(esi uint32, buf []byte) error {
+	for i := range buf {
+		buf[i] = 0
+	}
+	for _, i := range enc.ltIndices(esi, enc.j) {
+		xorSymbol(buf, enc.intermediate[i])
+	}
+	return nil
+}
+
+// blockDecoder collects encoding symbols of a source block, and solves for its
+// intermediate symbols once it has enough of them.
+type blockDecoder struct {
+	params
+	j       int
+	symbols map[uint32][]byte
+}
+
+func (dec *blockDecoder) Add(esi uint32, symbol []byte) raptorq.DecodeStatus {
+	if _, ok := dec.symbols[esi]; ok {
+		return raptorq.SymbolNotNeeded
+	}
+	dec.symbols[esi] = symbol
+	return raptorq.SymbolAccepted
+}
+
+func (dec *blockDecoder) Decode() (source [][]byte) {
+	if len(dec.symbols) < dec.k {
+		return
+	}
+	source = make([][]byte, dec.k)
+	complete := true
+	for esi := range source {
+		if source[esi] = dec.symbols[uint32(esi)]; source[esi] == nil {
+			complete = false
+		}
+	}
+	if complete {
+		return
+	}
+	rows := dec.constraintRows()
+	symbols := make([][]byte, 0, len(rows)+len(dec.symbols))
+	var symbolSize int
+	for _, symbol := range dec.symbols {
+		symbolSize = len(symbol)
+		break
+	}
+	for range rows {
+		symbols = append(symbols, make([]byte, symbolSize))
+	}
+	for esi, symbol := range dec.symbols {
+		rows = append(rows, dec.ltRow(esi, dec.j))
+		symbols = append(symbols, append([]byte(nil), symbol...))
+	}
+	intermediate := solve(rows, symbols, dec.l)
+	if intermediate == nil {
+		return nil
+	}
+	enc := blockEncoder{params: dec.params, j: dec.j, intermediate: intermediate}
+	for esi := range source {
+		if source[esi] == nil {
+			source[esi] = make([]byte, symbolSize)
+			_ = enc.Encode(uint32(esi), source[esi])
+		}
+	}
+	return
+}
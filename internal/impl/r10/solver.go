@@ -0,0 +1,58 @@
+package r10
+
+// bitSet is a row of a matrix over GF(2).
+type bitSet []uint64
+
+func newBitSet(n int) bitSet {
+	return make(bitSet, (n+63)/64)
+}
+
+func (s bitSet) get(i int) bool {
+	return s[i/64]>>uint(i%64)&1 != 0
+}
+
+func (s bitSet) flip(i int) {
+	s[i/64] ^= 1 << uint(i%64)
+}
+
+func (s bitSet) xor(t bitSet) {
+	for i := range s {
+		s[i] ^= t[i]
+	}
+}
+
+func xorSymbol(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// solve solves the system A·C = D over GF(2) for the n unknown symbols C, by
+// Gauss–Jordan elimination.  Each row of A is a bit set over the unknowns, and
+// each symbol of D is the XOR of the unknowns in its row.
+//
+// solve returns nil if A has rank less than n.  Both rows and symbols are
+// clobbered.
+func solve(rows []bitSet, symbols [][]byte, n int) (c [][]byte) {
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for r := col; r < len(rows); r++ {
+			if rows[r].get(col) {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil
+		}
+		rows[col], rows[pivot] = rows[pivot], rows[col]
+		symbols[col], symbols[pivot] = symbols[pivot], symbols[col]
+		for r := range rows {
+			if r != col && rows[r].get(col) {
+				rows[r].xor(rows[col])
+				xorSymbol(symbols[r], symbols[col])
+			}
+		}
+	}
+	return symbols[:n]
+}
@@ -0,0 +1,24 @@
+package r10
+
+// v0 and v1 are the random number tables V0 and V1 of RFC 5053 Section 5.6,
+// and systematicIndices are the systematic indices J(K) of Section 5.7, for K
+// from MinSourceSymbols through MaxSourceSymbols.
+//
+// They are not vendored yet.  Encoding symbols depend on every entry, so rather
+// than generate symbols that no other R10 implementation can decode, the
+// factories fail until the tables are filled in.
+var (
+	v0, v1            *[256]uint32
+	systematicIndices []uint16
+)
+
+// tablesAvailable returns whether the RFC 5053 tables have been filled in.
+func tablesAvailable() bool {
+	return v0 != nil && v1 != nil &&
+		len(systematicIndices) == MaxSourceSymbols-MinSourceSymbols+1
+}
+
+// systematicIndex returns J(k).
+func systematicIndex(k int) int {
+	return int(systematicIndices[k-MinSourceSymbols])
+}
@@ -0,0 +1,24 @@
+package rlc
+
+// maxDensity is the density threshold DT for which all coefficients are
+// non-zero.
+const maxDensity = 15
+
+// codingCoefficients returns the coefficients over GF(2^8) of the linear
+// combination of the given number of source symbols that makes up the repair
+// symbol of the given repair key and density threshold, as specified by
+// generate_coding_coefficients() of RFC 8681 Section 3.6 with m = 8.
+func codingCoefficients(repairKey uint16, n uint16, dt uint8) []uint8 {
+	coefs := make([]uint8, n)
+	s := newTinyMT32(uint32(repairKey))
+	for i := range coefs {
+		if dt < maxDensity && s.rand16() > dt {
+			continue
+		}
+		// Coefficient 0 is avoided, in order to include the source symbol.
+		for coefs[i] == 0 {
+			coefs[i] = s.rand256()
+		}
+	}
+	return coefs
+}
@@ -0,0 +1,266 @@
+package rlc
+
+import (
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/internal/gf256"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// DecoderFactory is a factory of RLC decoder instances.
+type DecoderFactory struct {
+}
+
+// New returns a new decoder instance.
+//
+// New returns an error if symbolSize is zero, or windowSize is zero or larger
+// than raptorq.MaxWindowSize.
+func (*DecoderFactory) New(symbolSize uint16, windowSize uint16) (
+	decoder raptorq.StreamDecoder, err error,
+) {
+	if err = checkParams(symbolSize, windowSize); err != nil {
+		return
+	}
+	decoder = &Decoder{
+		symbolSize: symbolSize,
+		windowSize: windowSize,
+		known:      make(map[uint32][]byte),
+		rows:       make(map[uint32]*equation),
+	}
+	return
+}
+
+// decodingWindowFactor is how many encoding windows' worth of source symbols
+// behind the latest one the decoder keeps, to make use of late packets.
+const decodingWindowFactor = 2
+
+// equation is a linear equation over the source symbols first, first+1, …,
+// first+len(coefs)-1, whose linear combination with coefs is data.
+type equation struct {
+	first uint32
+	coefs []uint8
+	data  []byte
+}
+
+// trim strips zero coefficients off both ends of the equation.
+func (e *equation) trim() {
+	i := 0
+	for i < len(e.coefs) && e.coefs[i] == 0 {
+		i++
+	}
+	e.first += uint32(i)
+	e.coefs = e.coefs[i:]
+	for len(e.coefs) > 0 && e.coefs[len(e.coefs)-1] == 0 {
+		e.coefs = e.coefs[:len(e.coefs)-1]
+	}
+}
+
+// coef returns the coefficient of the given source symbol.
+func (e *equation) coef(esi uint32) uint8 {
+	if esi < e.first || esi-e.first >= uint32(len(e.coefs)) {
+		return 0
+	}
+	return e.coefs[esi-e.first]
+}
+
+// subtract subtracts c times src from e.  src must not start before e.
+func (e *equation) subtract(src *equation, c uint8) {
+	offset := int(src.first - e.first)
+	if n := offset + len(src.coefs); n > len(e.coefs) {
+		e.coefs = append(e.coefs, make([]uint8, n-len(e.coefs))...)
+	}
+	gf256.MulAdd(e.coefs[offset:offset+len(src.coefs)], c, src.coefs)
+	gf256.MulAdd(e.data, c, src.data)
+}
+
+// Decoder is an RLC decoder instance.
+//
+// Decoder keeps the equations of received repair symbols in reduced row
+// echelon form over the source symbols not yet known, so that a source
+// symbol is recovered as soon as the equations received determine it.
+type Decoder struct {
+	symbolSize uint16
+	windowSize uint16
+	known      map[uint32][]byte
+	rows       map[uint32]*equation // by pivot
+	latest     uint32
+	lastPrune  uint32
+	closed     bool
+}
+
+// SymbolSize returns the symbol size, in octets.
+func (dec *Decoder) SymbolSize() uint16 {
+	return dec.symbolSize
+}
+
+// DecodeSourceSymbol decodes a received source symbol.
+func (dec *Decoder) DecodeSourceSymbol(esi uint32, symbol []byte) (
+	recovered []uint32,
+) {
+	dec.checkOpen()
+	if len(symbol) != int(dec.symbolSize) {
+		return
+	}
+	dec.advance(esi)
+	if esi < dec.horizon() {
+		return
+	}
+	if _, ok := dec.known[esi]; ok {
+		return
+	}
+	dec.known[esi] = append([]byte(nil), symbol...)
+	return dec.solve(dec.takeRowsWith(esi))
+}
+
+// DecodeRepairSymbol decodes a received repair symbol.
+func (dec *Decoder) DecodeRepairSymbol(
+	id raptorq.RepairID, symbol []byte,
+) (recovered []uint32) {
+	dec.checkOpen()
+	if len(symbol) != int(dec.symbolSize) || id.NumSourceSymbols == 0 ||
+		id.NumSourceSymbols > dec.windowSize || id.Density > maxDensity {
+		return
+	}
+	last := id.FirstESI + uint32(id.NumSourceSymbols) - 1
+	dec.advance(last)
+	if last < dec.horizon() {
+		return
+	}
+	e := &equation{
+		first: id.FirstESI,
+		coefs: codingCoefficients(id.Key, id.NumSourceSymbols, id.Density),
+		data:  append([]byte(nil), symbol...),
+	}
+	return dec.solve([]*equation{e})
+}
+
+// solve adds the given equations to the system, and returns the ESIs of the
+// source symbols recovered as a result.
+func (dec *Decoder) solve(queue []*equation) (recovered []uint32) {
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		dec.reduce(e)
+		if len(e.coefs) == 0 {
+			// Redundant.
+			continue
+		}
+		c := gf256.Inv(e.coefs[0])
+		gf256.MulSlice(e.coefs, c)
+		gf256.MulSlice(e.data, c)
+		if len(e.coefs) == 1 {
+			dec.known[e.first] = e.data
+			recovered = append(recovered, e.first)
+			queue = append(queue, dec.takeRowsWith(e.first)...)
+			continue
+		}
+		// Eliminate the new pivot from the other equations.
+		for _, row := range dec.rows {
+			if c := row.coef(e.first); c != 0 {
+				row.subtract(e, c)
+				if row.trim(); len(row.coefs) == 1 {
+					delete(dec.rows, row.first)
+					queue = append(queue, row)
+				}
+			}
+		}
+		dec.rows[e.first] = e
+	}
+	return
+}
+
+// reduce eliminates the known source symbols and the pivots of the equations
+// in the system from the given equation.
+func (dec *Decoder) reduce(e *equation) {
+	for i := 0; i < len(e.coefs); i++ {
+		c := e.coefs[i]
+		if c == 0 {
+			continue
+		}
+		esi := e.first + uint32(i)
+		if symbol, ok := dec.known[esi]; ok {
+			gf256.MulAdd(e.data, c, symbol)
+			e.coefs[i] = 0
+		} else if row, ok := dec.rows[esi]; ok {
+			e.subtract(row, c)
+		}
+	}
+	e.trim()
+}
+
+// takeRowsWith removes the equations involving the given source symbol from
+// the system, and returns them.
+func (dec *Decoder) takeRowsWith(esi uint32) (rows []*equation) {
+	for pivot, row := range dec.rows {
+		if row.coef(esi) != 0 {
+			delete(dec.rows, pivot)
+			rows = append(rows, row)
+		}
+	}
+	return
+}
+
+// horizon returns the ESI of the oldest source symbol the decoder keeps.
+func (dec *Decoder) horizon() uint32 {
+	span := uint32(dec.windowSize) * decodingWindowFactor
+	if dec.latest < span {
+		return 0
+	}
+	return dec.latest - span
+}
+
+// advance notes that the given source symbol has been sent, and forgets
+// source symbols and equations that slid out of the decoding window.
+func (dec *Decoder) advance(esi uint32) {
+	if esi <= dec.latest {
+		return
+	}
+	dec.latest = esi
+	if dec.latest-dec.lastPrune < uint32(dec.windowSize) {
+		return
+	}
+	dec.lastPrune = dec.latest
+	horizon := dec.horizon()
+	for esi := range dec.known {
+		if esi < horizon {
+			delete(dec.known, esi)
+		}
+	}
+	for pivot := range dec.rows {
+		if pivot < horizon {
+			delete(dec.rows, pivot)
+		}
+	}
+}
+
+// SourceSymbol copies the given source symbol into the given buffer.
+func (dec *Decoder) SourceSymbol(esi uint32, buf []byte) (n int, err error) {
+	dec.checkOpen()
+	symbol, ok := dec.known[esi]
+	switch {
+	case !ok:
+		err = raptorq.SourceSymbolNotReady{ESI: esi}
+	case len(buf) < len(symbol):
+		err = errors.New("RLC decoder buffer too small")
+	default:
+		n = copy(buf, symbol)
+	}
+	return
+}
+
+func (dec *Decoder) checkOpen() {
+	if dec.closed {
+		panic("RLC decoder already closed")
+	}
+}
+
+// Close closes the decoder.
+func (dec *Decoder) Close() (err error) {
+	if dec.closed {
+		err = errors.New("RLC decoder already closed")
+		return
+	}
+	dec.known, dec.rows = nil, nil
+	dec.closed = true
+	return
+}
@@ -0,0 +1,125 @@
+// Package rlc implements the Sliding Window Random Linear Codes over GF(2^8)
+// of RFC 8681 (FEC Encoding ID 10) behind raptorq.StreamEncoder and
+// raptorq.StreamDecoder.
+package rlc
+
+import (
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/internal/gf256"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// EncoderFactory is a factory of RLC encoder instances.
+type EncoderFactory struct {
+}
+
+// New returns a new encoder instance.
+//
+// New returns an error if symbolSize is zero, or windowSize is zero or larger
+// than raptorq.MaxWindowSize.
+func (*EncoderFactory) New(symbolSize uint16, windowSize uint16) (
+	encoder raptorq.StreamEncoder, err error,
+) {
+	if err = checkParams(symbolSize, windowSize); err != nil {
+		return
+	}
+	encoder = &Encoder{symbolSize: symbolSize, windowSize: windowSize}
+	return
+}
+
+func checkParams(symbolSize uint16, windowSize uint16) error {
+	switch {
+	case symbolSize == 0:
+		return errors.New("symbol size must not be zero")
+	case windowSize == 0 || windowSize > raptorq.MaxWindowSize:
+		return errors.New("window size out of range")
+	}
+	return nil
+}
+
+// Encoder is an RLC encoder instance.
+//
+// Encoder generates dense repair symbols (DT = 15), with consecutive repair
+// keys.
+type Encoder struct {
+	symbolSize uint16
+	windowSize uint16
+	window     [][]byte
+	firstESI   uint32
+	nextKey    uint16
+	closed     bool
+}
+
+// SymbolSize returns the symbol size, in octets.
+func (enc *Encoder) SymbolSize() uint16 {
+	return enc.symbolSize
+}
+
+// WindowSize returns the maximum encoding window size, in source symbols.
+func (enc *Encoder) WindowSize() uint16 {
+	return enc.windowSize
+}
+
+// AddSourceSymbol adds the given source symbol to the encoding window.
+func (enc *Encoder) AddSourceSymbol(symbol []byte) (esi uint32, err error) {
+	enc.checkOpen()
+	if len(symbol) != int(enc.symbolSize) {
+		err = errors.New("source symbol size mismatch")
+		return
+	}
+	if len(enc.window) == int(enc.windowSize) {
+		enc.window = enc.window[1:]
+		enc.firstESI++
+	}
+	esi = enc.firstESI + uint32(len(enc.window))
+	enc.window = append(enc.window, append([]byte(nil), symbol...))
+	return
+}
+
+// RepairSymbol writes a repair symbol over the current encoding window into
+// buf.
+func (enc *Encoder) RepairSymbol(buf []byte) (id raptorq.RepairID, err error) {
+	enc.checkOpen()
+	switch {
+	case len(enc.window) == 0:
+		err = errors.New("encoding window empty")
+		return
+	case len(buf) < int(enc.symbolSize):
+		err = errors.New("RLC encoder buffer too small")
+		return
+	}
+	id = raptorq.RepairID{
+		FirstESI:         enc.firstESI,
+		NumSourceSymbols: uint16(len(enc.window)),
+		Key:              enc.nextKey,
+		Density:          maxDensity,
+	}
+	enc.nextKey++
+	buf = buf[:enc.symbolSize]
+	for i := range buf {
+		buf[i] = 0
+	}
+	coefs := codingCoefficients(id.Key, id.NumSourceSymbols, id.Density)
+	for i, c := range coefs {
+		gf256.MulAdd(buf, c, enc.window[i])
+	}
+	return
+}
+
+func (enc *Encoder) checkOpen() {
+	if enc.closed {
+		panic("RLC encoder already closed")
+	}
+}
+
+// Close closes the encoder.
+func (enc *Encoder) Close() (err error) {
+	if enc.closed {
+		err = errors.New("RLC encoder already closed")
+		return
+	}
+	enc.window = nil
+	enc.closed = true
+	return
+}
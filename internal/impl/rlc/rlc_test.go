@@ -0,0 +1,158 @@
+package rlc
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+func TestTinyMT32(t *testing.T) {
+	// RFC 8682 Section 2.2: the first output for seed 1.
+	if got := newTinyMT32(1).uint32(); got != 2545341989 {
+		t.Errorf("first output for seed 1 = %d, want 2545341989", got)
+	}
+}
+
+func TestCodingCoefficients(t *testing.T) {
+	dense := codingCoefficients(1234, 100, 15)
+	for i, c := range dense {
+		if c == 0 {
+			t.Fatalf("dense coefficient %d is zero", i)
+		}
+	}
+	if !bytes.Equal(dense, codingCoefficients(1234, 100, 15)) {
+		t.Error("coefficients not deterministic")
+	}
+	zeros := 0
+	for _, c := range codingCoefficients(1234, 1000, 3) {
+		if c == 0 {
+			zeros++
+		}
+	}
+	// DT = 3 makes each coefficient non-zero with probability 4/16.
+	if zeros < 650 || zeros > 850 {
+		t.Errorf("%d zero coefficients out of 1000 for DT = 3", zeros)
+	}
+}
+
+func TestSmallWindow(t *testing.T) {
+	enc, err := (&EncoderFactory{}).New(4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	dec, err := (&DecoderFactory{}).New(4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	source := [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10, 11, 12}}
+	for _, s := range source {
+		if _, err := enc.AddSourceSymbol(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Lose source symbols 0 and 2; two repair symbols make up for them.
+	dec.DecodeSourceSymbol(1, source[1])
+	var recovered []uint32
+	for i := 0; i < 2; i++ {
+		buf := make([]byte, 4)
+		id, err := enc.RepairSymbol(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered = append(recovered, dec.DecodeRepairSymbol(id, buf)...)
+	}
+	if len(recovered) != 2 {
+		t.Fatalf("recovered %v, want source symbols 0 and 2", recovered)
+	}
+	for _, esi := range []uint32{0, 2} {
+		buf := make([]byte, 4)
+		if _, err := dec.SourceSymbol(esi, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, source[esi]) {
+			t.Errorf("source symbol %d = %v, want %v", esi, buf, source[esi])
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	const (
+		symbolSize = 32
+		windowSize = 16
+		numSource  = 2000
+		lossRate   = 0.1
+	)
+	rng := rand.New(rand.NewSource(1))
+	enc, err := (&EncoderFactory{}).New(symbolSize, windowSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	dec, err := (&DecoderFactory{}).New(symbolSize, windowSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	source := make([][]byte, numSource)
+	lost := make(map[uint32]bool)
+	recovered := make(map[uint32]bool)
+	buf := make([]byte, symbolSize)
+	check := func(esis []uint32) {
+		for _, esi := range esis {
+			recovered[esi] = true
+			if _, err := dec.SourceSymbol(esi, buf); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf, source[esi]) {
+				t.Errorf("source symbol %d mismatch", esi)
+			}
+		}
+	}
+	for i := range source {
+		source[i] = make([]byte, symbolSize)
+		rng.Read(source[i])
+		esi, err := enc.AddSourceSymbol(source[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if esi != uint32(i) {
+			t.Fatalf("ESI = %d, want %d", esi, i)
+		}
+		if rng.Float64() < lossRate {
+			lost[esi] = true
+		} else {
+			check(dec.DecodeSourceSymbol(esi, source[i]))
+		}
+		// One repair symbol after every 4 source symbols.
+		if i%4 == 3 {
+			repair := make([]byte, symbolSize)
+			id, err := enc.RepairSymbol(repair)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rng.Float64() < lossRate {
+				continue
+			}
+			// Round trip the repair ID through its wire format.
+			b := make([]byte, raptorq.RepairIDSize)
+			raptorq.PutRepairID(b, id)
+			if id, err = raptorq.ParseRepairID(b); err != nil {
+				t.Fatal(err)
+			}
+			check(dec.DecodeRepairSymbol(id, repair))
+		}
+	}
+	for esi := range recovered {
+		if !lost[esi] {
+			t.Errorf("source symbol %d recovered but not lost", esi)
+		}
+	}
+	if len(recovered) < len(lost)*9/10 {
+		t.Errorf("recovered %d of %d lost source symbols",
+			len(recovered), len(lost))
+	}
+}
@@ -0,0 +1,80 @@
+package rlc
+
+// TinyMT32 parameters of RFC 8682 Section 2.1.
+const (
+	tinymt32Mat1 = 0x8f7011ee
+	tinymt32Mat2 = 0xfc78ff1f
+	tinymt32TMat = 0x3793fdff
+
+	tinymt32Sh0  = 1
+	tinymt32Sh1  = 10
+	tinymt32Sh8  = 8
+	tinymt32Mask = 0x7fffffff
+
+	tinymt32MinLoop = 8
+	tinymt32PreLoop = 8
+)
+
+// tinymt32 is the TinyMT32 pseudorandom number generator of RFC 8682, with
+// the parameter set RFC 8681 mandates.
+type tinymt32 struct {
+	status [4]uint32
+}
+
+// newTinyMT32 returns a new generator initialized with the given seed.
+func newTinyMT32(seed uint32) *tinymt32 {
+	s := &tinymt32{status: [4]uint32{
+		seed, tinymt32Mat1, tinymt32Mat2, tinymt32TMat,
+	}}
+	for i := uint32(1); i < tinymt32MinLoop; i++ {
+		prev := s.status[(i-1)&3]
+		s.status[i&3] ^= i + 1812433253*(prev^(prev>>30))
+	}
+	// Period certification.
+	if s.status[0]&tinymt32Mask == 0 && s.status[1] == 0 &&
+		s.status[2] == 0 && s.status[3] == 0 {
+		s.status = [4]uint32{'T', 'I', 'N', 'Y'}
+	}
+	for i := 0; i < tinymt32PreLoop; i++ {
+		s.nextState()
+	}
+	return s
+}
+
+func (s *tinymt32) nextState() {
+	y := s.status[3]
+	x := s.status[0]&tinymt32Mask ^ s.status[1] ^ s.status[2]
+	x ^= x << tinymt32Sh0
+	y ^= y>>tinymt32Sh0 ^ x
+	s.status[0] = s.status[1]
+	s.status[1] = s.status[2]
+	s.status[2] = x ^ y<<tinymt32Sh1
+	s.status[3] = y
+	mask := -(y & 1)
+	s.status[1] ^= mask & tinymt32Mat1
+	s.status[2] ^= mask & tinymt32Mat2
+}
+
+func (s *tinymt32) temper() uint32 {
+	t0 := s.status[3]
+	t1 := s.status[0] + s.status[2]>>tinymt32Sh8
+	t0 ^= t1
+	t0 ^= -(t1 & 1) & tinymt32TMat
+	return t0
+}
+
+// uint32 returns the next 32-bit pseudorandom number.
+func (s *tinymt32) uint32() uint32 {
+	s.nextState()
+	return s.temper()
+}
+
+// rand16 returns the next pseudorandom number in [0, 15].
+func (s *tinymt32) rand16() uint8 {
+	return uint8(s.uint32() & 0xF)
+}
+
+// rand256 returns the next pseudorandom number in [0, 255].
+func (s *tinymt32) rand256() uint8 {
+	return uint8(s.uint32() & 0xFF)
+}
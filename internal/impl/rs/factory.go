@@ -0,0 +1,116 @@
+package rs
+
+import (
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/internal/blockcodec"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// MaxTransferLength is the largest source object size, in octets, that the
+// common OTI can convey.
+const MaxTransferLength = 1<<40 - 1
+
+// MaxSourceSymbols is the largest number of source symbols per source block,
+// “B” in RFC 6865.  A source block of this many source symbols has no room
+// left for repair symbols.
+const MaxSourceSymbols = MaxSymbols
+
+// The OTIs of Reed-Solomon objects carry the parameters of RFC 6865 Section
+// 5.1.1.2 in the integers of the raptorq interfaces, as follows:
+//
+//	common OTI:          F (40 bits) || T (16 bits) in the low 56 bits
+//	scheme-specific OTI: 0 (8 bits) || B (8 bits) || max_n (8 bits) || m (8 bits)
+//
+// The source object is split into source blocks of at most B source symbols
+// by the block partitioning algorithm of RFC 5052 Section 9.1, with no
+// sub-blocks.  The top octet is zero, unlike that of RaptorQ, whose number of
+// source blocks is at least 1.
+
+// m is the Reed-Solomon symbol size, in bits.
+const m = 8
+
+func commonOTI(transferLength uint64, symbolSize uint16) uint64 {
+	return transferLength<<24 | uint64(symbolSize)
+}
+
+func schemeSpecificOTI(b uint8) uint32 {
+	return uint32(b)<<16 | MaxSymbols<<8 | m
+}
+
+// newLayout returns the layout of an object of the given OTI parameters.
+func newLayout(transferLength uint64, symbolSize uint16, b uint8) (
+	l *blockcodec.Layout, err error,
+) {
+	if transferLength == 0 || transferLength > MaxTransferLength ||
+		symbolSize == 0 || b == 0 {
+		err = errors.New("Reed-Solomon object parameters out of range")
+		return
+	}
+	kt := (transferLength + uint64(symbolSize) - 1) / uint64(symbolSize)
+	z := (kt + uint64(b) - 1) / uint64(b)
+	if z > 255 {
+		err = errors.New("too many Reed-Solomon source blocks")
+		return
+	}
+	return blockcodec.NewLayout(transferLength, symbolSize, uint8(z), 1, 1,
+		commonOTI(transferLength, symbolSize), schemeSpecificOTI(b))
+}
+
+// EncoderFactory is a factory of Reed-Solomon encoder instances.
+type EncoderFactory struct {
+}
+
+// New returns a new encoder instance.
+//
+// New splits the source object into source blocks of at most
+// maxSubBlockSize octets, and at most MaxSourceSymbols source symbols.  There
+// are no sub-blocks, so minSubSymbolSize is ignored, and alignment only needs
+// to divide symbolSize.
+func (*EncoderFactory) New(input []byte, symbolSize uint16,
+	minSubSymbolSize uint16, maxSubBlockSize uint32, alignment uint8,
+) (enc raptorq.Encoder, err error) {
+	if alignment == 0 || symbolSize%uint16(alignment) != 0 {
+		err = errors.New("symbol size must be a multiple of alignment")
+		return
+	}
+	b := maxSubBlockSize / uint32(symbolSize)
+	switch {
+	case b == 0:
+		err = errors.New("maximum sub-block size smaller than symbol size")
+		return
+	case b > MaxSourceSymbols:
+		b = MaxSourceSymbols
+	}
+	layout, err := newLayout(uint64(len(input)), symbolSize, uint8(b))
+	if err != nil {
+		return
+	}
+	enc = blockcodec.NewEncoder(scheme{}, layout, input, maxSubBlockSize)
+	return
+}
+
+// DecoderFactory is a factory of Reed-Solomon decoder instances.
+type DecoderFactory struct {
+}
+
+// New returns a new decoder instance for the given Reed-Solomon OTIs.
+//
+// New returns an error if the OTIs are malformed or out of range.
+func (*DecoderFactory) New(commonOTI uint64, schemeSpecificOTI uint32) (
+	dec raptorq.Decoder, err error,
+) {
+	if commonOTI>>56 != 0 || schemeSpecificOTI>>24 != 0 ||
+		uint8(schemeSpecificOTI>>8) != MaxSymbols ||
+		uint8(schemeSpecificOTI) != m {
+		err = errors.New("not a Reed-Solomon OTI")
+		return
+	}
+	layout, err := newLayout(commonOTI>>24, uint16(commonOTI),
+		uint8(schemeSpecificOTI>>16))
+	if err != nil {
+		return
+	}
+	dec, err = blockcodec.NewDecoder(scheme{}, layout)
+	return
+}
@@ -0,0 +1,17 @@
+package rs
+
+import "github.com/harmony-one/go-raptorq/pkg/registry"
+
+func init() {
+	registry.Register(registry.Codec{
+		Name: registry.ReedSolomon,
+		Capabilities: registry.Capabilities{
+			FECEncodingID:    8,
+			MaxSourceSymbols: MaxSourceSymbols,
+			MaxSymbols:       MaxSymbols,
+			MDS:              true,
+		},
+		EncoderFactory: &EncoderFactory{},
+		DecoderFactory: &DecoderFactory{},
+	})
+}
@@ -0,0 +1,327 @@
+package rs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+func TestRoundTrip(t *testing.T) {
+	const symbolSize = 32
+	input := make([]byte, 10*symbolSize-7)
+	rand.New(rand.NewSource(1)).Read(input)
+	// Source blocks of 4, 3 and 3 source symbols.
+	enc, err := (&EncoderFactory{}).New(input, symbolSize, symbolSize,
+		4*symbolSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	if enc.NumSourceBlocks() != 3 || enc.SchemeSpecificOTI()>>24 != 0 {
+		t.Fatalf("Z = %d, scheme-specific OTI = %#x",
+			enc.NumSourceBlocks(), enc.SchemeSpecificOTI())
+	}
+	buf := make([]byte, symbolSize)
+	if _, err := enc.Encode(0, 1, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, input[symbolSize:2*symbolSize]) {
+		t.Error("source symbol 1 differs from the source object")
+	}
+	dec, err := (&DecoderFactory{}).New(enc.CommonOTI(), enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	for sbn := uint8(0); sbn < enc.NumSourceBlocks(); sbn++ {
+		// Exactly K symbols, mostly repair, recover the source block.
+		k := uint32(enc.NumSourceSymbols(sbn))
+		for i := uint32(0); i < k; i++ {
+			esi := 1 + i*37%(MaxSymbols-1)
+			if _, err := enc.Encode(sbn, esi, buf); err != nil {
+				t.Fatal(err)
+			}
+			if dec.IsSourceBlockReady(sbn) {
+				t.Fatalf("source block %d ready after %d symbols", sbn, i)
+			}
+			dec.Decode(sbn, esi, buf)
+		}
+		if !dec.IsSourceBlockReady(sbn) {
+			t.Fatalf("source block %d not ready after K symbols", sbn)
+		}
+	}
+	output := make([]byte, len(input))
+	if _, err := dec.SourceObject(output); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, input) {
+		t.Fatal("recovered source object differs")
+	}
+}
+
+func TestBadOTI(t *testing.T) {
+	// A RaptorQ scheme-specific OTI: one source block, one sub-block.
+	if _, err := (&DecoderFactory{}).New(1000<<24|100, 1<<24|1<<8|1); err == nil {
+		t.Error("RaptorQ OTI accepted")
+	}
+}
+
+const testSymbolSize = 32
+
+// newTestCodec returns a source object of source blocks of 4, 3 and 3 source
+// symbols, with an encoder and a decoder for it.
+func newTestCodec(t *testing.T) (
+	input []byte, enc raptorq.Encoder, dec raptorq.Decoder,
+) {
+	input = make([]byte, 10*testSymbolSize-7)
+	rand.New(rand.NewSource(2)).Read(input)
+	enc, err := (&EncoderFactory{}).New(input, testSymbolSize, testSymbolSize,
+		4*testSymbolSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err = (&DecoderFactory{}).New(enc.CommonOTI(), enc.SchemeSpecificOTI())
+	if err != nil {
+		enc.Close()
+		t.Fatal(err)
+	}
+	return
+}
+
+// feed feeds the given encoding symbols of a source block to dec.
+func feed(t *testing.T, enc raptorq.Encoder, dec raptorq.Decoder,
+	sbn uint8, esis ...uint32,
+) {
+	for _, esi := range esis {
+		buf := make([]byte, testSymbolSize)
+		if _, err := enc.Encode(sbn, esi, buf); err != nil {
+			t.Fatal(err)
+		}
+		dec.Decode(sbn, esi, buf)
+	}
+}
+
+func TestEndOfInput(t *testing.T) {
+	input, enc, dec := newTestCodec(t)
+	defer enc.Close()
+	defer dec.Close()
+	// Source block 0: two source symbols and a repair symbol, one short.
+	feed(t, enc, dec, 0, 0, 2, 100)
+	known, err := dec.EndOfInput(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, false, true, false}; !reflect.DeepEqual(known, want) {
+		t.Errorf("source block 0: known = %v, want %v", known, want)
+	}
+	buf := make([]byte, 4*testSymbolSize)
+	if _, err := dec.SourceBlock(0, buf); err != raptorq.SourceBlockNotReady(0) {
+		t.Errorf("source block 0 without zero-fill: err = %v", err)
+	}
+	// Source block 1: one source symbol, zero-filled.
+	feed(t, enc, dec, 1, 1)
+	if known, err = dec.EndOfInput(1, true); err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{false, true, false}; !reflect.DeepEqual(known, want) {
+		t.Errorf("source block 1: known = %v, want %v", known, want)
+	}
+	buf = make([]byte, 3*testSymbolSize)
+	if _, err := dec.SourceBlock(1, buf); err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, 3*testSymbolSize)
+	copy(want[testSymbolSize:2*testSymbolSize],
+		input[5*testSymbolSize:6*testSymbolSize])
+	if !bytes.Equal(buf, want) {
+		t.Error("zero-filled source block 1 differs")
+	}
+	if dec.IsSourceBlockReady(1) {
+		t.Error("zero-filled source block 1 ready")
+	}
+	// Source block 2: recovered from repair symbols, so every source symbol
+	// is known.
+	feed(t, enc, dec, 2, 10, 11, 12)
+	if known, err = dec.EndOfInput(2, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, true, true}; !reflect.DeepEqual(known, want) {
+		t.Errorf("source block 2: known = %v, want %v", known, want)
+	}
+	if _, err := dec.EndOfInput(3, false); err == nil {
+		t.Error("source block 3 out of range but no error")
+	}
+}
+
+func TestReadAt(t *testing.T) {
+	input, enc, dec := newTestCodec(t)
+	defer enc.Close()
+	defer dec.Close()
+	// Source block 0 recovered, only source symbol 0 of source block 1
+	// received, and nothing of source block 2 yet.
+	feed(t, enc, dec, 0, 0, 1, 2, 3)
+	feed(t, enc, dec, 1, 0)
+	// Across the boundary between source blocks 0 and 1.
+	p := make([]byte, 60)
+	if n, err := dec.ReadAt(p, 100); err != nil || n != 60 {
+		t.Fatalf("ReadAt(100) = %d, %v", n, err)
+	}
+	if !bytes.Equal(p, input[100:160]) {
+		t.Error("ReadAt(100) differs from the source object")
+	}
+	// Past source symbol 0 of source block 1.
+	p = make([]byte, 70)
+	n, err := dec.ReadAt(p, 100)
+	if err != raptorq.SourceBlockNotReady(1) || n != 60 {
+		t.Fatalf("ReadAt(100) = %d, %v, want 60, source block 1 not ready",
+			n, err)
+	}
+	if !bytes.Equal(p[:n], input[100:160]) {
+		t.Error("short ReadAt(100) differs from the source object")
+	}
+	if _, err := dec.ReadAt(p, 8*testSymbolSize); err != raptorq.SourceBlockNotReady(2) {
+		t.Errorf("ReadAt in source block 2: err = %v", err)
+	}
+	// Source block 2 recovered from repair symbols, up to the end.
+	feed(t, enc, dec, 2, 20, 21, 22)
+	p = make([]byte, 20)
+	off := int64(len(input) - 13)
+	if n, err := dec.ReadAt(p, off); err != io.EOF || n != 13 {
+		t.Fatalf("ReadAt(%d) = %d, %v, want 13, EOF", off, n, err)
+	}
+	if !bytes.Equal(p[:13], input[off:]) {
+		t.Error("ReadAt at the end differs from the source object")
+	}
+	if _, err := dec.ReadAt(p, int64(len(input))); err != io.EOF {
+		t.Errorf("ReadAt past the end: err = %v", err)
+	}
+	if _, err := dec.ReadAt(p, -1); err == nil {
+		t.Error("ReadAt(-1) succeeded")
+	}
+}
+
+func TestReader(t *testing.T) {
+	input, enc, dec := newTestCodec(t)
+	defer enc.Close()
+	defer dec.Close()
+	type result struct {
+		output []byte
+		err    error
+	}
+	done := make(chan result, 1)
+	r := dec.Reader(context.Background())
+	go func() {
+		output, err := ioutil.ReadAll(r)
+		done <- result{output, err}
+	}()
+	// Source blocks become ready out of order.
+	feed(t, enc, dec, 2, 0, 1, 2)
+	feed(t, enc, dec, 1, 30, 31, 32)
+	select {
+	case res := <-done:
+		t.Fatalf("reader finished before source block 0: %d, %v",
+			len(res.output), res.err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	feed(t, enc, dec, 0, 0, 1, 2, 3)
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if !bytes.Equal(res.output, input) {
+		t.Error("streamed source object differs")
+	}
+	buf := make([]byte, 4*testSymbolSize)
+	if _, err := dec.SourceBlock(0, buf); err == nil {
+		t.Error("source block 0 not freed after reading")
+	}
+}
+
+func TestReaderCancel(t *testing.T) {
+	input, enc, dec := newTestCodec(t)
+	defer enc.Close()
+	defer dec.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := dec.Reader(ctx)
+	feed(t, enc, dec, 0, 0, 1, 2, 3)
+	p := make([]byte, 4*testSymbolSize)
+	if n, err := io.ReadFull(r, p); err != nil {
+		t.Fatalf("read %d octets: %v", n, err)
+	}
+	if !bytes.Equal(p, input[:len(p)]) {
+		t.Error("source block 0 differs")
+	}
+	cancel()
+	if _, err := r.Read(p); err != context.Canceled {
+		t.Errorf("read after cancel: err = %v", err)
+	}
+}
+
+func TestReaderClose(t *testing.T) {
+	_, enc, dec := newTestCodec(t)
+	defer enc.Close()
+	r := dec.Reader(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Read(make([]byte, testSymbolSize))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := dec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err == nil || err == io.EOF {
+			t.Errorf("read after closing the decoder: err = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("read still blocked after closing the decoder")
+	}
+}
+
+func TestSourceSymbol(t *testing.T) {
+	input, enc, dec := newTestCodec(t)
+	defer enc.Close()
+	defer dec.Close()
+	feed(t, enc, dec, 0, 1, 100)
+	buf := make([]byte, testSymbolSize)
+	if n, err := dec.SourceSymbol(0, 1, buf); err != nil || n != testSymbolSize {
+		t.Fatalf("SourceSymbol(0, 1) = %d, %v", n, err)
+	}
+	if !bytes.Equal(buf, input[testSymbolSize:2*testSymbolSize]) {
+		t.Error("received source symbol 1 differs")
+	}
+	want := raptorq.SourceSymbolNotReady{SBN: 0, ESI: 0}
+	if _, err := dec.SourceSymbol(0, 0, buf); err != want {
+		t.Errorf("SourceSymbol(0, 0) before recovery: err = %v", err)
+	}
+	// Recovered source symbols, including the padded last one.
+	feed(t, enc, dec, 0, 101, 102)
+	feed(t, enc, dec, 2, 50, 51, 52)
+	if _, err := dec.SourceSymbol(0, 0, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, input[:testSymbolSize]) {
+		t.Error("recovered source symbol 0 differs")
+	}
+	if _, err := dec.SourceSymbol(2, 2, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf[:testSymbolSize-7], input[9*testSymbolSize:]) {
+		t.Error("recovered last source symbol differs")
+	}
+	if _, err := dec.SourceSymbol(0, 4, buf); err == nil {
+		t.Error("SourceSymbol(0, 4) out of range but no error")
+	}
+	if _, err := dec.SourceSymbol(0, 0, buf[:1]); err == nil {
+		t.Error("SourceSymbol into a short buffer but no error")
+	}
+}
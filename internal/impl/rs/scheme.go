@@ -0,0 +1,174 @@
+// Package rs implements the Reed-Solomon code over GF(2^8) of RFC 6865 and RFC
+// 5510 behind raptorq.Encoder and raptorq.Decoder.
+//
+// The code is MDS: any K encoding symbols of a source block recover it, with
+// none of the reception overhead of fountain codes, and set-up costs little
+// for small K.  Encoding and decoding time grows with K², though, and a source
+// block yields at most 255 encoding symbols, so the code suits objects of a
+// handful of symbols.
+package rs
+
+import (
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/internal/blockcodec"
+	"github.com/harmony-one/go-raptorq/internal/gf256"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// MaxSymbols is the number of encoding symbols per source block, “max_n” in
+// RFC 6865, which is 2^m - 1 for m = 8.
+const MaxSymbols = 255
+
+// The generator matrix of RFC 5510 Section 8.3 is GM = V(k, k)⁻¹ · V(k, n),
+// where V(k, n) is the k × n Vandermonde matrix of element (i, j) α^(i·j).
+// Encoding symbol j is therefore the value at α^j of the polynomial p of degree
+// less than k whose values at α^0 … α^(k-1) are the source symbols:
+//
+//	y_j = Σ_l p_l · α^(j·l)
+//
+// Both the encoder and the decoder find p by solving a Vandermonde system, of
+// the source symbols and of the received symbols respectively.
+
+// scheme is the Reed-Solomon code as a blockcodec.Scheme.
+type scheme struct{}
+
+func (scheme) NewBlockEncoder(source [][]byte) (
+	enc blockcodec.BlockEncoder, err error,
+) {
+	esis := make([]uint32, len(source))
+	for esi := range esis {
+		esis[esi] = uint32(esi)
+	}
+	p := interpolate(esis, source)
+	if p == nil {
+		err = errors.New("singular Reed-Solomon matrix")
+		return
+	}
+	enc = &blockEncoder{p}
+	return
+}
+
+func (scheme) NewBlockDecoder(numSourceSymbols uint16, symbolSize uint16) (
+	dec blockcodec.BlockDecoder, err error,
+) {
+	if numSourceSymbols > MaxSymbols {
+		err = errors.New("too many Reed-Solomon source symbols")
+		return
+	}
+	dec = &blockDecoder{
+		k:       int(numSourceSymbols),
+		symbols: make(map[uint32][]byte),
+	}
+	return
+}
+
+// MinSymbols returns K; any K encoding symbols recover the source block.
+func (scheme) MinSymbols(numSourceSymbols uint16) uint16 {
+	return numSourceSymbols
+}
+
+func (scheme) MaxSymbols(numSourceSymbols uint16) uint32 {
+	return MaxSymbols
+}
+
+// interpolate returns the coefficients of the polynomial of degree less than
+// len(esis) whose value at α^esis[i] is symbols[i], or nil if esis are not
+// distinct.
+func interpolate(esis []uint32, symbols [][]byte) (p [][]byte) {
+	k := len(esis)
+	rows := make([][]byte, k)
+	p = make([][]byte, k)
+	for i, esi := range esis {
+		rows[i] = make([]byte, k)
+		for l := range rows[i] {
+			rows[i][l] = gf256.Exp(int(esi) * l)
+		}
+		p[i] = append([]byte(nil), symbols[i]...)
+	}
+	// Gauss–Jordan elimination.
+	for col := 0; col < k; col++ {
+		pivot := -1
+		for r := col; r < k; r++ {
+			if rows[r][col] != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil
+		}
+		rows[col], rows[pivot] = rows[pivot], rows[col]
+		p[col], p[pivot] = p[pivot], p[col]
+		inv := gf256.Inv(rows[col][col])
+		gf256.MulSlice(rows[col], inv)
+		gf256.MulSlice(p[col], inv)
+		for r := 0; r < k; r++ {
+			if c := rows[r][col]; r != col && c != 0 {
+				gf256.MulAdd(rows[r], c, rows[col])
+				gf256.MulAdd(p[r], c, p[col])
+			}
+		}
+	}
+	return
+}
+
+// evaluate writes the value of the polynomial p at α^esi into buf.
+func evaluate(p [][]byte, esi uint32, buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+	for l, coef := range p {
+		gf256.MulAdd(buf, gf256.Exp(int(esi)*l), coef)
+	}
+}
+
+// blockEncoder generates encoding symbols of a source block from its
+// polynomial.
+type blockEncoder struct {
+	p [][]byte
+}
+
+func (enc *blockEncoder) Encode(esi uint32, buf []byte) error {
+	evaluate(enc.p, esi, buf)
+	return nil
+}
+
+// blockDecoder collects encoding symbols of a source block until it has K of
+// them.
+type blockDecoder struct {
+	k       int
+	symbols map[uint32][]byte
+}
+
+func (dec *blockDecoder) Add(esi uint32, symbol []byte) raptorq.DecodeStatus {
+	if _, ok := dec.symbols[esi]; ok || len(dec.symbols) >= dec.k {
+		return raptorq.SymbolNotNeeded
+	}
+	dec.symbols[esi] = symbol
+	return raptorq.SymbolAccepted
+}
+
+func (dec *blockDecoder) Decode() (source [][]byte) {
+	if len(dec.symbols) < dec.k {
+		return
+	}
+	esis := make([]uint32, 0, dec.k)
+	symbols := make([][]byte, 0, dec.k)
+	source = make([][]byte, dec.k)
+	for esi, symbol := range dec.symbols {
+		esis = append(esis, esi)
+		symbols = append(symbols, symbol)
+		if esi < uint32(dec.k) {
+			source[esi] = symbol
+		}
+	}
+	p := interpolate(esis, symbols)
+	for esi := range source {
+		if source[esi] == nil {
+			source[esi] = make([]byte, len(symbols[0]))
+			evaluate(p, uint32(esi), source[esi])
+		}
+	}
+	return
+}
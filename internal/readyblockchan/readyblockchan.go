@@ -25,16 +25,46 @@ func (e NotFound) Error() string {
 type ReadyBlockChannels struct {
 	mutex    sync.Mutex
 	ready    []bool
-	channels []chan<- uint8
+	channels []*channel
+}
+
+// channel is a ready-block channel along with the goroutines sending into it.
+type channel struct {
+	ch      chan<- uint8
+	quit    chan struct{}
+	senders sync.WaitGroup
+}
+
+// send sends the given block number into the channel from a goroutine of its
+// own, unless the channel is closed meanwhile.
+//
+// The caller must hold the mutex of the collection.
+func (c *channel) send(sbn uint8) {
+	c.senders.Add(1)
+	go func() {
+		defer c.senders.Done()
+		select {
+		case c.ch <- sbn:
+		case <-c.quit:
+		}
+	}()
+}
+
+// close stops pending sends, then closes the channel.
+func (c *channel) close() {
+	close(c.quit)
+	c.senders.Wait()
+	close(c.ch)
 }
 
 // Reset resets this instance.  Existing channels are closed and removed,
-// and all blocks are reset as not received.
+// and all blocks are reset as not received.  Block numbers not yet taken
+// from the channels are dropped.
 func (rbcs *ReadyBlockChannels) Reset(numSourceBlocks uint8) {
 	rbcs.mutex.Lock()
 	defer rbcs.mutex.Unlock()
-	for _, ch := range rbcs.channels {
-		close(ch)
+	for _, c := range rbcs.channels {
+		c.close()
 	}
 	rbcs.channels = nil
 	rbcs.ready = make([]bool, numSourceBlocks)
@@ -51,16 +81,17 @@ func (rbcs *ReadyBlockChannels) AddChannel(ch chan<- uint8) (
 ) {
 	rbcs.mutex.Lock()
 	defer rbcs.mutex.Unlock()
-	for _, ch1 := range rbcs.channels {
-		if ch == ch1 {
+	for _, c := range rbcs.channels {
+		if ch == c.ch {
 			err = AlreadyAdded(ch)
 			return
 		}
 	}
-	rbcs.channels = append(rbcs.channels, ch)
+	c := &channel{ch: ch, quit: make(chan struct{})}
+	rbcs.channels = append(rbcs.channels, c)
 	for sbn, ready := range rbcs.ready {
 		if ready {
-			go addBlockToChan(uint8(sbn), ch)
+			c.send(uint8(sbn))
 		}
 	}
 	return
@@ -77,8 +108,8 @@ func (rbcs *ReadyBlockChannels) RemoveChannel(ch chan<- uint8) (
 	rbcs.mutex.Lock()
 	defer rbcs.mutex.Unlock()
 	var idx = -1
-	for i, ch1 := range rbcs.channels {
-		if ch == ch1 {
+	for i, c := range rbcs.channels {
+		if ch == c.ch {
 			idx = i
 			break
 		}
@@ -104,11 +135,7 @@ func (rbcs *ReadyBlockChannels) AddBlock(sbn uint8) {
 		return
 	}
 	rbcs.ready[sbn] = true
-	for _, ch := range rbcs.channels {
-		go addBlockToChan(sbn, ch)
+	for _, c := range rbcs.channels {
+		c.send(sbn)
 	}
 }
-
-func addBlockToChan(sbn uint8, ch chan<- uint8) {
-	ch <- sbn
-}
@@ -0,0 +1,101 @@
+package rfc6330
+
+import "sort"
+
+// KPrime returns K′, the smallest supported number of source symbols per
+// extended source block that is not less than k, or 0 if k is larger than the
+// largest supported K′.
+func KPrime(k uint32) uint16 {
+	i := sort.Search(len(KPrimes), func(i int) bool {
+		return uint32(KPrimes[i]) >= k
+	})
+	if i == len(KPrimes) {
+		return 0
+	}
+	return KPrimes[i]
+}
+
+// maxKPrime returns the largest supported K′ not greater than k, or 0 if k is
+// smaller than the smallest supported K′.
+func maxKPrime(k uint64) uint16 {
+	i := sort.Search(len(KPrimes), func(i int) bool {
+		return uint64(KPrimes[i]) > k
+	})
+	if i == 0 {
+		return 0
+	}
+	return KPrimes[i-1]
+}
+
+// kl is KL(n) in RFC 6330 Section 4.4.1.2.
+func kl(n uint64, symbolSize uint16, maxSubBlockSize uint32, al uint8) uint16 {
+	units := (uint64(symbolSize) + uint64(al)*n - 1) / (uint64(al) * n)
+	return maxKPrime(uint64(maxSubBlockSize) / (uint64(al) * units))
+}
+
+// Partition derives the number of source blocks Z and the number of sub-blocks
+// N that an RFC 6330 sender uses for an object of the given transfer length,
+// from the sender parameters of RFC 6330 Section 4.3.
+//
+// minSubSymbolSize (SS·Al) and maxSubBlockSize (WS) are in octets, the
+// same as the arguments to raptorq.EncoderFactory.
+//
+// Partition returns z = 0 if the parameters cannot partition the object.
+func Partition(
+	transferLength uint64, symbolSize uint16, minSubSymbolSize uint16,
+	maxSubBlockSize uint32, al uint8,
+) (z uint32, n uint16) {
+	if symbolSize == 0 || minSubSymbolSize == 0 || al == 0 {
+		return
+	}
+	kt := (transferLength + uint64(symbolSize) - 1) / uint64(symbolSize)
+	nMax := uint64(symbolSize / minSubSymbolSize)
+	if nMax == 0 {
+		return
+	}
+	klMax := kl(nMax, symbolSize, maxSubBlockSize, al)
+	if klMax == 0 {
+		return
+	}
+	z64 := (kt + uint64(klMax) - 1) / uint64(klMax)
+	if z64 == 0 || z64 > 1<<32-1 {
+		return
+	}
+	kPerBlock := (kt + z64 - 1) / z64
+	for n64 := uint64(1); n64 <= nMax; n64++ {
+		if kPerBlock <= uint64(kl(n64, symbolSize, maxSubBlockSize, al)) {
+			return uint32(z64), uint16(n64)
+		}
+	}
+	return
+}
+
+// SingleBlockParams returns the sender parameters that make an RFC 6330
+// sender encode an object of k source symbols as exactly one source block of
+// n sub-blocks, so that the encoding symbols it generates match those of a
+// source block of k source symbols and n sub-blocks in a larger object.
+//
+// The returned minSubSymbolSize and maxSubBlockSize are in octets.
+// SingleBlockParams returns ok = false if no such parameters exist.
+func SingleBlockParams(k uint16, symbolSize uint16, n uint16, al uint8) (
+	minSubSymbolSize uint16, maxSubBlockSize uint32, ok bool,
+) {
+	if k == 0 || n == 0 || al == 0 || symbolSize < uint16(al)*n {
+		return
+	}
+	kPrime := KPrime(uint32(k))
+	if kPrime == 0 {
+		return
+	}
+	// Splitting T into n sub-symbols must be possible (Nmax ≥ n), and the
+	// smallest WS that fits K′ symbols of n sub-blocks keeps every n′ < n
+	// from fitting k symbols, the same way it did for the sender.
+	minSubSymbolSize = symbolSize / n
+	units := (uint32(symbolSize) + uint32(al)*uint32(n) - 1) /
+		(uint32(al) * uint32(n))
+	maxSubBlockSize = uint32(kPrime) * uint32(al) * units
+	z, n1 := Partition(uint64(k)*uint64(symbolSize), symbolSize,
+		minSubSymbolSize, maxSubBlockSize, al)
+	ok = z == 1 && n1 == n
+	return
+}
@@ -0,0 +1,47 @@
+package rfc6330
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestKPrime(t *testing.T) {
+	for _, c := range []struct {
+		k    uint32
+		want uint16
+	}{
+		{0, 10}, {1, 10}, {10, 10}, {11, 12}, {56403, 56403}, {56404, 0},
+	} {
+		if got := KPrime(c.k); got != c.want {
+			t.Errorf("KPrime(%d) = %d, want %d", c.k, got, c.want)
+		}
+	}
+}
+
+func TestSingleBlockParams(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		al := uint8(1 << uint(rng.Intn(3)))
+		symbolSize := uint16(al) * uint16(1+rng.Intn(400))
+		minSubSymbolSize := uint16(al) * uint16(1+rng.Intn(int(symbolSize/uint16(al))))
+		maxSubBlockSize := uint32(symbolSize) * uint32(10+rng.Intn(3000))
+		transferLength := uint64(1 + rng.Intn(1<<24))
+		z, n := Partition(transferLength, symbolSize, minSubSymbolSize,
+			maxSubBlockSize, al)
+		if z == 0 || z > 256 {
+			continue
+		}
+		kt := (transferLength + uint64(symbolSize) - 1) / uint64(symbolSize)
+		for _, k := range []uint64{(kt + uint64(z) - 1) / uint64(z), kt / uint64(z)} {
+			if k == 0 {
+				continue
+			}
+			_, _, ok := SingleBlockParams(uint16(k), symbolSize, n, al)
+			if !ok {
+				t.Fatalf("no single-block parameters for K=%d T=%d N=%d Al=%d "+
+					"(F=%d SS·Al=%d WS=%d)", k, symbolSize, n, al,
+					transferLength, minSubSymbolSize, maxSubBlockSize)
+			}
+		}
+	}
+}
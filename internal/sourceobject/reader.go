@@ -0,0 +1,93 @@
+package sourceobject
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// Reader reads the source object from a decoder strictly in source block
+// order, waiting for each source block to become ready.
+//
+// Reader frees each source block in the decoder once it has been copied out.
+type Reader struct {
+	ctx       context.Context
+	dec       raptorq.Decoder
+	readyChan chan uint8
+	ready     []bool
+	sbn       int
+	block     []byte
+	err       error
+}
+
+// NewReader returns a new reader over the given decoder.
+//
+// ctx bounds how long Read waits for source blocks to become ready.
+func NewReader(ctx context.Context, dec raptorq.Decoder) *Reader {
+	numSourceBlocks := int(dec.NumSourceBlocks())
+	r := &Reader{
+		ctx: ctx,
+		dec: dec,
+		// Big enough for every block, so notifications never block.
+		readyChan: make(chan uint8, numSourceBlocks),
+		ready:     make([]bool, numSourceBlocks),
+	}
+	r.err = dec.AddReadyBlockChan(r.readyChan)
+	return r
+}
+
+// Read reads the next part of the source object into p.
+//
+// Read blocks until the next source block is ready, or the context is done,
+// in which case it returns the context error.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	for len(r.block) == 0 {
+		if r.err != nil {
+			err = r.err
+			return
+		}
+		if r.sbn == len(r.ready) {
+			r.finish(io.EOF)
+			continue
+		}
+		if err = r.waitForBlock(uint8(r.sbn)); err != nil {
+			return
+		}
+		sbn := uint8(r.sbn)
+		block := make([]byte, r.dec.SourceBlockSize(sbn))
+		if _, err = r.dec.SourceBlock(sbn, block); err != nil {
+			r.finish(err)
+			return
+		}
+		r.dec.FreeSourceBlock(sbn)
+		r.block = block
+		r.sbn++
+	}
+	n = copy(p, r.block)
+	r.block = r.block[n:]
+	return
+}
+
+func (r *Reader) waitForBlock(sbn uint8) error {
+	for !r.ready[sbn] && !r.dec.IsSourceBlockReady(sbn) {
+		select {
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		case ready, ok := <-r.readyChan:
+			if !ok {
+				// Closed (and removed) by the decoder.
+				r.err = errors.New("decoder closed before source object was read")
+				return r.err
+			}
+			r.ready[ready] = true
+		}
+	}
+	return nil
+}
+
+func (r *Reader) finish(err error) {
+	r.err = err
+	_ = r.dec.RemoveReadyBlockChan(r.readyChan)
+}
@@ -0,0 +1,107 @@
+// Package sourceobject provides a mix-in that implements source object access
+// of raptorq.Decoder on top of its per-source-block methods.
+package sourceobject
+
+import (
+	"errors"
+	"io"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// BlockReadFunc reads len(p) octets of the given source block into p,
+// starting at octet offset off within the source block.  It stops at the first
+// source symbol not available, and returns the number of octets read along
+// with an error if it stopped short.
+type BlockReadFunc func(sbn uint8, p []byte, off int64) (n int, err error)
+
+// ReadAt reads len(p) octets of the source object decoded by dec into p,
+// starting at octet offset off, with the semantics of io.ReaderAt.
+//
+// ReadAt reads each source block covering the range using readBlock, so that
+// only the requested octets are copied out of the decoder.  If readBlock is
+// nil, ReadAt reads source symbol by source symbol using dec.SourceSymbol.
+//
+// ReadAt succeeds as long as the source symbols covering the range are
+// available, whether their source blocks are ready or not.  Otherwise it
+// returns the octets up to the first source symbol not available, along with
+// raptorq.SourceBlockNotReady error naming its source block.
+func ReadAt(
+	dec raptorq.Decoder, readBlock BlockReadFunc, p []byte, off int64,
+) (n int, err error) {
+	if off < 0 {
+		err = errors.New("negative offset")
+		return
+	}
+	size := int64(dec.TransferLength())
+	if off >= size {
+		err = io.EOF
+		return
+	}
+	if readBlock == nil {
+		readBlock = func(sbn uint8, p []byte, off int64) (int, error) {
+			return readSymbols(dec, sbn, p, off)
+		}
+	}
+	end := off + int64(len(p))
+	if end > size {
+		end = size
+	}
+	var blockStart int64
+	for sbn := 0; sbn < int(dec.NumSourceBlocks()); sbn++ {
+		if off+int64(n) >= end {
+			break
+		}
+		blockEnd := blockStart + int64(dec.SourceBlockSize(uint8(sbn)))
+		if blockEnd <= off {
+			blockStart = blockEnd
+			continue
+		}
+		stop := end
+		if stop > blockEnd {
+			stop = blockEnd
+		}
+		var m int
+		m, err = readBlock(uint8(sbn), p[n:stop-off], off+int64(n)-blockStart)
+		n += m
+		if err != nil {
+			err = raptorq.SourceBlockNotReady(sbn)
+			return
+		}
+		blockStart = blockEnd
+	}
+	if n < len(p) {
+		err = io.EOF
+	}
+	return
+}
+
+// readSymbols fills p with the given source block contents starting at octet
+// offset off within the block, using individual source symbols.  Whole source
+// symbols are read straight into p.
+func readSymbols(dec raptorq.Decoder, sbn uint8, p []byte, off int64) (
+	n int, err error,
+) {
+	symbolSize := int64(dec.SymbolSize())
+	var symbol []byte
+	for n < len(p) {
+		pos := off + int64(n)
+		esi := pos / symbolSize
+		skip := pos - esi*symbolSize
+		if skip == 0 && int64(len(p)-n) >= symbolSize {
+			if _, err = dec.SourceSymbol(sbn, uint32(esi), p[n:]); err != nil {
+				return
+			}
+			n += int(symbolSize)
+			continue
+		}
+		if symbol == nil {
+			symbol = make([]byte, symbolSize)
+		}
+		if _, err = dec.SourceSymbol(sbn, uint32(esi), symbol); err != nil {
+			return
+		}
+		n += copy(p[n:], symbol[skip:])
+	}
+	return
+}
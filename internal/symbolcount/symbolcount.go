@@ -0,0 +1,71 @@
+// Package symbolcount provides a mix-in that implements per-block received
+// symbol tracking of raptorq.Decoder.
+package symbolcount
+
+import "sync"
+
+// Counters counts the encoding symbols accepted by a decoder, per source
+// block, and tracks which source symbols have been received.
+type Counters struct {
+	mutex    sync.Mutex
+	counts   []uint32
+	received [][]bool
+}
+
+// Reset resets this instance.  All counts are reset to zero, and all source
+// symbols are reset as not received.
+func (c *Counters) Reset(numSourceBlocks uint8) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.counts = make([]uint32, numSourceBlocks)
+	c.received = make([][]bool, numSourceBlocks)
+}
+
+// Add counts one encoding symbol accepted for the given source block, of
+// numSourceSymbols source symbols.
+//
+// Add ignores out-of-range source block numbers.
+func (c *Counters) Add(sbn uint8, esi uint32, numSourceSymbols uint16) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if int(sbn) >= len(c.counts) {
+		return
+	}
+	c.counts[sbn]++
+	if esi < uint32(numSourceSymbols) {
+		if c.received[sbn] == nil {
+			c.received[sbn] = make([]bool, numSourceSymbols)
+		}
+		c.received[sbn][esi] = true
+	}
+}
+
+// Count returns the number of encoding symbols accepted for the given source
+// block, or 0 if sbn is out of range.
+func (c *Counters) Count(sbn uint8) uint32 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if int(sbn) < len(c.counts) {
+		return c.counts[sbn]
+	}
+	return 0
+}
+
+// Missing returns, in ascending order, the ESIs of the source symbols not
+// received for the given source block, of numSourceSymbols source symbols.
+func (c *Counters) Missing(sbn uint8, numSourceSymbols uint16) (
+	esis []uint32,
+) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if int(sbn) >= len(c.received) {
+		return
+	}
+	received := c.received[sbn]
+	for esi := uint32(0); esi < uint32(numSourceSymbols); esi++ {
+		if received == nil || !received[esi] {
+			esis = append(esis, esi)
+		}
+	}
+	return
+}
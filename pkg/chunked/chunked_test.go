@@ -0,0 +1,97 @@
+package chunked
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+)
+
+func TestRoundTrip(t *testing.T) {
+	input := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(input)
+	enc, err := NewEncoder(input, Config{
+		Factory:          defaults.ReedSolomonEncoderFactory(),
+		ChunkSize:        3000,
+		SymbolSize:       100,
+		MinSubSymbolSize: 100,
+		MaxSubBlockSize:  3000,
+		Alignment:        1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	if enc.NumChunks() != 4 {
+		t.Fatalf("%d chunks, want 4", enc.NumChunks())
+	}
+	for chunk, chunkEnc := range enc.chunks {
+		if chunkEnc != nil {
+			t.Errorf("encoder of chunk %d held before use", chunk)
+		}
+	}
+	data, err := enc.Manifest().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest Manifest
+	if err := manifest.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	dec, err := NewDecoder(&manifest, defaults.ReedSolomonDecoderFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	buf := make([]byte, 100)
+	for chunk := 0; chunk < enc.NumChunks(); chunk++ {
+		chunkEnc, err := enc.Chunk(chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Repair symbols only.
+		k := uint32(chunkEnc.NumSourceSymbols(0))
+		for esi := k; esi < 2*k; esi++ {
+			if _, err := enc.Encode(chunk, 0, esi, buf); err != nil {
+				t.Fatal(err)
+			}
+			dec.Decode(chunk, 0, esi, buf)
+		}
+		enc.FreeChunk(chunk)
+	}
+	if !dec.IsObjectReady() {
+		t.Fatal("input not recovered")
+	}
+	output := make([]byte, len(input))
+	if _, err := dec.Object(output); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, input) {
+		t.Fatal("recovered input differs")
+	}
+	dec.manifest.Chunks[2].Hash[0] ^= 1
+	if _, err := dec.Object(output); err != HashMismatch(2) {
+		t.Errorf("Object() error = %v, want %v", err, HashMismatch(2))
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	m := Manifest{TransferLength: 10, Chunks: []ChunkInfo{{Size: 4}, {Size: 5}}}
+	if err := m.Validate(); err == nil {
+		t.Error("chunk sizes short of the transfer length accepted")
+	}
+	data, _ := m.MarshalBinary()
+	if err := m.UnmarshalBinary(data); err == nil {
+		t.Error("invalid manifest unmarshaled")
+	}
+	m.Chunks[1].Size = 6
+	data, _ = m.MarshalBinary()
+	if err := m.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	data[3] = 1
+	if err := m.UnmarshalBinary(data); err == nil {
+		t.Error("manifest with reserved fields set unmarshaled")
+	}
+}
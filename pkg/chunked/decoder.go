@@ -0,0 +1,213 @@
+package chunked
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+// HashMismatch signals the given chunk, although decoded, does not match its
+// hash in the manifest.
+type HashMismatch int
+
+func (e HashMismatch) Error() string {
+	return fmt.Sprintf("chunk %d hash mismatch", int(e))
+}
+
+// ChunkNotReady signals the given chunk has not been recovered yet.
+type ChunkNotReady int
+
+func (e ChunkNotReady) Error() string {
+	return fmt.Sprintf("chunk %d not ready", int(e))
+}
+
+// Decoder reassembles an input from its chunks.
+//
+// Decoder creates the decoder of each chunk when its first symbol arrives.
+type Decoder struct {
+	manifest Manifest
+	offsets  []uint64
+	factory  raptorq.DecoderFactory
+	mutex    sync.Mutex
+	chunks   []raptorq.Decoder
+	closed   bool
+}
+
+// NewDecoder returns a decoder of the input described by the given manifest.
+// If factory is nil, that of the default codec is used; NewDecoder fails if
+// there is none, see defaults.LookupCodec.
+func NewDecoder(manifest *Manifest, factory raptorq.DecoderFactory) (
+	dec *Decoder, err error,
+) {
+	if err = manifest.Validate(); err != nil {
+		return
+	}
+	if factory == nil {
+		var codec registry.Codec
+		if codec, err = defaults.LookupCodec(); err != nil {
+			return
+		}
+		factory = codec.DecoderFactory
+	}
+	dec = &Decoder{
+		manifest: *manifest,
+		offsets:  make([]uint64, len(manifest.Chunks)),
+		factory:  factory,
+		chunks:   make([]raptorq.Decoder, len(manifest.Chunks)),
+	}
+	var offset uint64
+	for i, c := range manifest.Chunks {
+		dec.offsets[i] = offset
+		offset += c.Size
+	}
+	return
+}
+
+// Manifest returns the manifest of the input.
+func (dec *Decoder) Manifest() *Manifest {
+	return &dec.manifest
+}
+
+// Chunk returns the decoder of the given chunk, creating one if needed.
+//
+// The returned decoder is owned by dec; callers must not close it.
+func (dec *Decoder) Chunk(chunk int) (chunkDec raptorq.Decoder, err error) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	if dec.closed {
+		panic("chunked decoder already closed")
+	}
+	if chunk < 0 || chunk >= len(dec.chunks) {
+		err = errors.New("chunk number out of range")
+		return
+	}
+	if chunkDec = dec.chunks[chunk]; chunkDec != nil {
+		return
+	}
+	c := &dec.manifest.Chunks[chunk]
+	if chunkDec, err = dec.factory.New(c.CommonOTI, c.SchemeSpecificOTI); err != nil {
+		return
+	}
+	if chunkDec.TransferLength() != c.Size {
+		_ = chunkDec.Close()
+		chunkDec = nil
+		err = fmt.Errorf("chunk %d OTI disagrees with its size", chunk)
+		return
+	}
+	dec.chunks[chunk] = chunkDec
+	return
+}
+
+// Decode decodes the given encoding symbol of the given chunk, and returns
+// its status.
+func (dec *Decoder) Decode(chunk int, sbn uint8, esi uint32, symbol []byte) (
+	status raptorq.DecodeStatus,
+) {
+	chunkDec, err := dec.Chunk(chunk)
+	if err != nil {
+		return raptorq.SymbolRejected
+	}
+	return chunkDec.DecodeBatch([]raptorq.Symbol{
+		{SBN: sbn, ESI: esi, Data: symbol},
+	})[0]
+}
+
+// IsChunkReady returns whether the given chunk has been recovered.  It does
+// not check the chunk hash; ChunkData does.
+func (dec *Decoder) IsChunkReady(chunk int) bool {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	return chunk >= 0 && chunk < len(dec.chunks) && dec.chunks[chunk] != nil &&
+		dec.chunks[chunk].IsSourceObjectReady()
+}
+
+// IsObjectReady returns whether all chunks have been recovered.
+func (dec *Decoder) IsObjectReady() bool {
+	for chunk := range dec.manifest.Chunks {
+		if !dec.IsChunkReady(chunk) {
+			return false
+		}
+	}
+	return true
+}
+
+// ChunkData copies the given chunk into buf, which should have room for its
+// size in the manifest, and checks it against its hash.
+//
+// ChunkData returns a ChunkNotReady error if the chunk has not been
+// recovered, or a HashMismatch error if the recovered chunk is not the one the
+// manifest describes.
+func (dec *Decoder) ChunkData(chunk int, buf []byte) (n int, err error) {
+	if !dec.IsChunkReady(chunk) {
+		err = ChunkNotReady(chunk)
+		return
+	}
+	chunkDec, err := dec.Chunk(chunk)
+	if err != nil {
+		return
+	}
+	size := dec.manifest.Chunks[chunk].Size
+	if uint64(len(buf)) < size {
+		err = errors.New("chunked decoder buffer too small")
+		return
+	}
+	if n, err = chunkDec.SourceObject(buf[:size]); err != nil {
+		return
+	}
+	if sha256.Sum256(buf[:n]) != dec.manifest.Chunks[chunk].Hash {
+		err = HashMismatch(chunk)
+	}
+	return
+}
+
+// Object reassembles the input into buf, which should have room for the
+// transfer length in the manifest.
+func (dec *Decoder) Object(buf []byte) (n int, err error) {
+	if uint64(len(buf)) < dec.manifest.TransferLength {
+		err = errors.New("chunked decoder buffer too small")
+		return
+	}
+	for chunk := range dec.manifest.Chunks {
+		var m int
+		if m, err = dec.ChunkData(chunk, buf[dec.offsets[chunk]:]); err != nil {
+			return
+		}
+		n += m
+	}
+	return
+}
+
+// FreeChunk closes the decoder of the given chunk, e.g. once its data has been
+// retrieved.  Symbols of the chunk fed afterwards start a new decoder.
+func (dec *Decoder) FreeChunk(chunk int) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	if chunk < 0 || chunk >= len(dec.chunks) || dec.chunks[chunk] == nil {
+		return
+	}
+	_ = dec.chunks[chunk].Close()
+	dec.chunks[chunk] = nil
+}
+
+// Close closes the decoders of all chunks.
+func (dec *Decoder) Close() (err error) {
+	dec.mutex.Lock()
+	defer dec.mutex.Unlock()
+	if dec.closed {
+		err = errors.New("chunked decoder already closed")
+		return
+	}
+	for _, chunkDec := range dec.chunks {
+		if chunkDec != nil {
+			_ = chunkDec.Close()
+		}
+	}
+	dec.chunks = nil
+	dec.closed = true
+	return
+}
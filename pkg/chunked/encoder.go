@@ -0,0 +1,196 @@
+package chunked
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+// DefaultChunkSize is the default chunk size, in octets.
+const DefaultChunkSize = 64 << 20
+
+// Config is the encoder configuration.
+type Config struct {
+	// Factory creates the encoder of each chunk.  If nil, that of the
+	// default codec is used; NewEncoder fails if there is none, see
+	// defaults.LookupCodec.
+	Factory raptorq.EncoderFactory
+
+	// ChunkSize is the size of each chunk but the last, in octets.  If
+	// zero, DefaultChunkSize is used.  It must be small enough for the
+	// factory to encode a chunk as one object with the parameters below.
+	ChunkSize uint64
+
+	// SymbolSize, MinSubSymbolSize, MaxSubBlockSize and Alignment are passed
+	// to the factory for each chunk.
+	SymbolSize       uint16
+	MinSubSymbolSize uint16
+	MaxSubBlockSize  uint32
+	Alignment        uint8
+}
+
+// Encoder encodes an input as chunks.
+//
+// Encoder creates the encoder of each chunk upfront in order to fill in the
+// manifest, then closes it, so that only the chunks being sent hold encoder
+// memory.  Chunk and Encode create the encoder of a chunk again when needed,
+// and FreeChunk closes it.
+type Encoder struct {
+	cfg      Config
+	input    []byte
+	manifest Manifest
+	mutex    sync.Mutex
+	chunks   []raptorq.Encoder
+	closed   bool
+}
+
+// NewEncoder returns an encoder of the given input.
+func NewEncoder(input []byte, cfg Config) (enc *Encoder, err error) {
+	if len(input) == 0 {
+		err = errors.New("empty input")
+		return
+	}
+	if cfg.Factory == nil {
+		var codec registry.Codec
+		if codec, err = defaults.LookupCodec(); err != nil {
+			return
+		}
+		cfg.Factory = codec.EncoderFactory
+	}
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = DefaultChunkSize
+	}
+	numChunks := (uint64(len(input)) + cfg.ChunkSize - 1) / cfg.ChunkSize
+	if numChunks > 1<<32-1 {
+		err = errors.New("too many chunks")
+		return
+	}
+	enc = &Encoder{
+		cfg:   cfg,
+		input: input,
+		manifest: Manifest{
+			TransferLength: uint64(len(input)),
+			Chunks:         make([]ChunkInfo, numChunks),
+		},
+		chunks: make([]raptorq.Encoder, numChunks),
+	}
+	for i := range enc.chunks {
+		data := enc.chunkData(i)
+		var chunkEnc raptorq.Encoder
+		if chunkEnc, err = enc.newChunkEncoder(data); err != nil {
+			enc = nil
+			return
+		}
+		enc.manifest.Chunks[i] = ChunkInfo{
+			Size:              uint64(len(data)),
+			CommonOTI:         chunkEnc.CommonOTI(),
+			SchemeSpecificOTI: chunkEnc.SchemeSpecificOTI(),
+			Hash:              sha256.Sum256(data),
+		}
+		_ = chunkEnc.Close()
+	}
+	return
+}
+
+// chunkData returns the input data of the given chunk.
+func (enc *Encoder) chunkData(chunk int) []byte {
+	start := uint64(chunk) * enc.cfg.ChunkSize
+	end := start + enc.cfg.ChunkSize
+	if end > uint64(len(enc.input)) {
+		end = uint64(len(enc.input))
+	}
+	return enc.input[start:end]
+}
+
+func (enc *Encoder) newChunkEncoder(data []byte) (raptorq.Encoder, error) {
+	return enc.cfg.Factory.New(data, enc.cfg.SymbolSize,
+		enc.cfg.MinSubSymbolSize, enc.cfg.MaxSubBlockSize, enc.cfg.Alignment)
+}
+
+// Manifest returns the manifest of the input.
+func (enc *Encoder) Manifest() *Manifest {
+	return &enc.manifest
+}
+
+// NumChunks returns the number of chunks.
+func (enc *Encoder) NumChunks() int {
+	return len(enc.manifest.Chunks)
+}
+
+// Chunk returns the encoder of the given chunk, creating it if it has not
+// been created since NewEncoder or FreeChunk.
+//
+// The returned encoder is owned by enc; callers must not close it.
+func (enc *Encoder) Chunk(chunk int) (chunkEnc raptorq.Encoder, err error) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if enc.closed {
+		panic("chunked encoder already closed")
+	}
+	if chunk < 0 || chunk >= len(enc.chunks) {
+		err = errors.New("chunk number out of range")
+		return
+	}
+	if chunkEnc = enc.chunks[chunk]; chunkEnc != nil {
+		return
+	}
+	if chunkEnc, err = enc.newChunkEncoder(enc.chunkData(chunk)); err != nil {
+		return
+	}
+	info := &enc.manifest.Chunks[chunk]
+	if chunkEnc.CommonOTI() != info.CommonOTI ||
+		chunkEnc.SchemeSpecificOTI() != info.SchemeSpecificOTI {
+		_ = chunkEnc.Close()
+		chunkEnc = nil
+		err = errors.New("chunk encoder OTIs differ from the manifest")
+		return
+	}
+	enc.chunks[chunk] = chunkEnc
+	return
+}
+
+// Encode writes the encoding symbol identified by the given chunk, source
+// block number and encoding symbol ID into buf.
+func (enc *Encoder) Encode(chunk int, sbn uint8, esi uint32, buf []byte) (
+	written uint, err error,
+) {
+	chunkEnc, err := enc.Chunk(chunk)
+	if err != nil {
+		return
+	}
+	return chunkEnc.Encode(sbn, esi, buf)
+}
+
+// FreeChunk closes the encoder of the given chunk.
+func (enc *Encoder) FreeChunk(chunk int) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if chunk < 0 || chunk >= len(enc.chunks) || enc.chunks[chunk] == nil {
+		return
+	}
+	_ = enc.chunks[chunk].Close()
+	enc.chunks[chunk] = nil
+}
+
+// Close closes the encoders of all chunks.
+func (enc *Encoder) Close() (err error) {
+	enc.mutex.Lock()
+	defer enc.mutex.Unlock()
+	if enc.closed {
+		err = errors.New("chunked encoder already closed")
+		return
+	}
+	for _, chunkEnc := range enc.chunks {
+		if chunkEnc != nil {
+			_ = chunkEnc.Close()
+		}
+	}
+	enc.chunks = nil
+	enc.input = nil
+	enc.closed = true
+	return
+}
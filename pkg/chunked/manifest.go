@@ -0,0 +1,143 @@
+// Package chunked transfers inputs too large for one RaptorQ object, by
+// splitting them into chunks, each encoded as an object of its own, and
+// describing the chunks in a manifest.
+//
+// RFC 6330 caps an object at 946270874880 octets, in at most 256 source
+// blocks of at most 56403 source symbols each; a chunk is kept well within
+// those limits.  The receiver needs the manifest, sent out of band or as an
+// object of its own, before it can decode any chunk.
+package chunked
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// HashSize is the size of a chunk hash, in octets.
+const HashSize = sha256.Size
+
+// ChunkInfo describes one chunk.
+type ChunkInfo struct {
+	// Size is the chunk size, in octets.
+	Size uint64
+
+	// CommonOTI and SchemeSpecificOTI are the OTIs of the object encoding
+	// the chunk.
+	CommonOTI         uint64
+	SchemeSpecificOTI uint32
+
+	// Hash is the SHA-256 hash of the chunk.
+	Hash [HashSize]byte
+}
+
+// ManifestVersion is the version of the manifest format.
+const ManifestVersion = 1
+
+const (
+	manifestHeaderSize = 16
+	chunkInfoSize      = 52
+)
+
+// Manifest describes how an input has been split into chunks.  Its wire
+// format is, in network byte order:
+//
+//	Version                  (8 bits)
+//	Reserved                 (24 bits)
+//	Number of chunks         (32 bits)
+//	Transfer length          (64 bits)
+//
+// and, for each chunk:
+//
+//	Size                     (64 bits)
+//	Common OTI               (64 bits)
+//	Scheme-specific OTI      (32 bits)
+//	SHA-256 hash             (256 bits)
+//
+// Chunks are listed in input order, and their sizes add up to the transfer
+// length.  Reserved bits are zero; receivers reject manifests where they are
+// not.
+type Manifest struct {
+	TransferLength uint64
+	Chunks         []ChunkInfo
+}
+
+// Validate returns an error if the chunk sizes do not add up to the transfer
+// length, or a chunk is empty.
+func (m *Manifest) Validate() error {
+	var total uint64
+	for i, c := range m.Chunks {
+		if c.Size == 0 {
+			return fmt.Errorf("chunk %d empty", i)
+		}
+		total += c.Size
+		if total < c.Size {
+			return errors.New("chunk sizes overflow")
+		}
+	}
+	if total != m.TransferLength {
+		return errors.New("chunk sizes do not add up to the transfer length")
+	}
+	return nil
+}
+
+// MarshalBinary encodes the manifest.
+func (m *Manifest) MarshalBinary() (data []byte, err error) {
+	if uint64(len(m.Chunks)) > 1<<32-1 {
+		err = errors.New("too many chunks")
+		return
+	}
+	data = make([]byte, manifestHeaderSize, manifestHeaderSize+
+		len(m.Chunks)*chunkInfoSize)
+	data[0] = ManifestVersion
+	binary.BigEndian.PutUint32(data[4:], uint32(len(m.Chunks)))
+	binary.BigEndian.PutUint64(data[8:], m.TransferLength)
+	var b [chunkInfoSize]byte
+	for _, c := range m.Chunks {
+		binary.BigEndian.PutUint64(b[0:], c.Size)
+		binary.BigEndian.PutUint64(b[8:], c.CommonOTI)
+		binary.BigEndian.PutUint32(b[16:], c.SchemeSpecificOTI)
+		copy(b[20:], c.Hash[:])
+		data = append(data, b[:]...)
+	}
+	return
+}
+
+// UnmarshalBinary decodes and validates the manifest.
+func (m *Manifest) UnmarshalBinary(data []byte) (err error) {
+	if len(data) < manifestHeaderSize {
+		err = errors.New("manifest too short")
+		return
+	}
+	if data[0] != ManifestVersion {
+		err = fmt.Errorf("unsupported manifest version %d", data[0])
+		return
+	}
+	if data[1] != 0 || data[2] != 0 || data[3] != 0 {
+		err = errors.New("manifest reserved fields not zero")
+		return
+	}
+	n := uint64(binary.BigEndian.Uint32(data[4:]))
+	if uint64(len(data)) != manifestHeaderSize+n*chunkInfoSize {
+		err = errors.New("manifest size mismatch")
+		return
+	}
+	decoded := Manifest{
+		TransferLength: binary.BigEndian.Uint64(data[8:]),
+		Chunks:         make([]ChunkInfo, n),
+	}
+	b := data[manifestHeaderSize:]
+	for i := range decoded.Chunks {
+		c := &decoded.Chunks[i]
+		c.Size = binary.BigEndian.Uint64(b[0:])
+		c.CommonOTI = binary.BigEndian.Uint64(b[8:])
+		c.SchemeSpecificOTI = binary.BigEndian.Uint32(b[16:])
+		copy(c.Hash[:], b[20:chunkInfoSize])
+		b = b[chunkInfoSize:]
+	}
+	if err = decoded.Validate(); err == nil {
+		*m = decoded
+	}
+	return
+}
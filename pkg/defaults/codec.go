@@ -0,0 +1,95 @@
+package defaults
+
+import "errors"
+import "os"
+import "sync"
+import "github.com/harmony-one/go-raptorq/pkg/registry"
+
+// CodecEnv is the environment variable that names the default codec, unless
+// Use has been called.
+const CodecEnv = "GO_RAPTORQ_CODEC"
+
+var (
+	codecMutex sync.Mutex
+	codecName  string // set by Use
+)
+
+// Use makes the codec registered under the given name the default codec.
+//
+// Use returns an error if no codec is registered under the name, e.g. because
+// it needs cgo and the build has none.
+func Use(name string) (err error) {
+	if _, ok := registry.Lookup(name); !ok {
+		err = errors.New("codec " + name + " not registered")
+		return
+	}
+	codecMutex.Lock()
+	codecName = name
+	codecMutex.Unlock()
+	return
+}
+
+// LookupCodec returns the default codec, chosen in order of precedence:
+//
+//  1. the codec last passed to Use;
+//  2. the codec named by the CodecEnv environment variable;
+//  3. libRaptorQ if the build has cgo.
+//
+// LookupCodec returns an error if CodecEnv names a codec not registered, or
+// if none of the above applies, as in builds without cgo: the pure-Go codecs
+// suit too few source objects to be picked silently.
+func LookupCodec() (codec registry.Codec, err error) {
+	codecMutex.Lock()
+	name := codecName
+	codecMutex.Unlock()
+	if name == "" {
+		if name = os.Getenv(CodecEnv); name == "" {
+			name = buildCodec
+		} else if _, ok := registry.Lookup(name); !ok {
+			err = errors.New("codec " + name + " named by " + CodecEnv +
+				" not registered")
+			return
+		}
+	}
+	if name == "" {
+		err = errors.New("no default codec in builds without cgo; " +
+			"call Use or set " + CodecEnv)
+		return
+	}
+	codec, ok := registry.Lookup(name)
+	if !ok {
+		err = errors.New("default codec " + name + " not registered")
+	}
+	return
+}
+
+// Codec is like LookupCodec, but panics if there is no default codec.
+func Codec() registry.Codec {
+	codec, err := LookupCodec()
+	if err != nil {
+		panic("defaults: " + err.Error())
+	}
+	return codec
+}
+
+// RaptorQCodec returns the RaptorQ codec of the build: the default codec if it
+// is RaptorQ, or else the first registered codec of FEC Encoding ID 6.
+//
+// Protocols whose wire format only suits RaptorQ use it rather than
+// LookupCodec.
+// RaptorQCodec returns an error if there is none, e.g. in builds without cgo.
+func RaptorQCodec() (codec registry.Codec, err error) {
+	const raptorQ = 6 // FEC Encoding ID, RFC 6330 Section 3.1
+	if def, lookupErr := LookupCodec(); lookupErr == nil &&
+		def.Capabilities.FECEncodingID == raptorQ {
+		codec = def
+		return
+	}
+	for _, codec = range registry.Codecs() {
+		if codec.Capabilities.FECEncodingID == raptorQ {
+			return
+		}
+	}
+	codec, err = registry.Codec{}, errors.New("no RaptorQ codec in this build")
+	return
+}
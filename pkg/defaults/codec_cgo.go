@@ -0,0 +1,24 @@
+//go:build cgo
+// +build cgo
+
+package defaults
+
+import "github.com/harmony-one/go-raptorq/internal/impl/libraptorq"
+import "github.com/harmony-one/go-raptorq/pkg/raptorq"
+import "github.com/harmony-one/go-raptorq/pkg/registry"
+
+// buildCodec is the name of the default codec of cgo builds.
+const buildCodec = registry.LibRaptorQ
+
+// DefaultV1EncoderFactory is the default factory of encoders of the
+// RaptorQ__v1 API, for objects of more blocks than RFC 6330 allows.  It is
+// only available in cgo builds.
+func DefaultV1EncoderFactory() raptorq.V1EncoderFactory {
+	return &libraptorq.V1EncoderFactory{}
+}
+
+// DefaultV1DecoderFactory is the default factory of decoders of the
+// RaptorQ__v1 API.  It is only available in cgo builds.
+func DefaultV1DecoderFactory() raptorq.V1DecoderFactory {
+	return &libraptorq.V1DecoderFactory{}
+}
@@ -0,0 +1,10 @@
+//go:build !cgo
+// +build !cgo
+
+package defaults
+
+// buildCodec is empty in builds without cgo, which cannot link libRaptorQ:
+// Reed-Solomon, the only pure-Go codec whose symbols other implementations can
+// decode, caps source blocks at 255 symbols, so callers must opt into it with
+// Use or CodecEnv.
+const buildCodec = ""
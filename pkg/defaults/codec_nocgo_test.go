@@ -0,0 +1,43 @@
+//go:build !cgo
+// +build !cgo
+
+package defaults
+
+import (
+	"os"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+func TestSelectingFactoriesWithoutCgo(t *testing.T) {
+	os.Unsetenv(CodecEnv)
+	if _, err := SelectingEncoderFactory(); err == nil {
+		t.Error("selecting encoder factory without a default codec")
+	}
+	if _, err := SelectingDecoderFactory(); err == nil {
+		t.Error("selecting decoder factory without a default codec")
+	}
+	defer func() { codecName = "" }()
+	if err := Use(registry.ReedSolomon); err != nil {
+		t.Fatal(err)
+	}
+	encFactory, err := SelectingEncoderFactory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := encFactory.New(make([]byte, 1000), 100, 100, 1000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	decFactory, err := SelectingDecoderFactory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := decFactory.New(enc.CommonOTI(), enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec.Close()
+}
@@ -0,0 +1,49 @@
+package defaults
+
+import (
+	"os"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+func TestCodec(t *testing.T) {
+	defer func() { codecName = "" }()
+	os.Setenv(CodecEnv, registry.ReedSolomon)
+	defer os.Unsetenv(CodecEnv)
+	if name := Codec().Name; name != registry.ReedSolomon {
+		t.Errorf("codec = %q with %s set, want %q",
+			name, CodecEnv, registry.ReedSolomon)
+	}
+	os.Setenv(CodecEnv, "no such codec")
+	if codec, err := LookupCodec(); err == nil {
+		t.Errorf("codec = %q with bad %s, want error", codec.Name, CodecEnv)
+	}
+	os.Unsetenv(CodecEnv)
+	codec, err := LookupCodec()
+	switch {
+	case buildCodec == "" && err == nil:
+		t.Errorf("codec = %q without cgo, want error", codec.Name)
+	case buildCodec != "" && (err != nil || codec.Name != buildCodec):
+		t.Errorf("codec = %q, %v, want %q", codec.Name, err, buildCodec)
+	}
+	if buildCodec == "" {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Codec without cgo did not panic")
+				}
+			}()
+			Codec()
+		}()
+	}
+	if err := Use("no such codec"); err == nil {
+		t.Error("Use of unregistered codec succeeded")
+	}
+	if err := Use(registry.ReedSolomon); err != nil {
+		t.Fatal(err)
+	}
+	if name := Codec().Name; name != registry.ReedSolomon {
+		t.Errorf("codec = %q after Use, want %q", name, registry.ReedSolomon)
+	}
+}
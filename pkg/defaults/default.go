@@ -1,33 +1,108 @@
 package defaults
 
 import "github.com/harmony-one/go-raptorq/pkg/raptorq"
-import "github.com/harmony-one/go-raptorq/internal/impl/libraptorq"
 
-// DefaultEncoderFactory is the default encoder factory.
+// R10 registers itself only once the RFC 5053 tables are vendored.
+import _ "github.com/harmony-one/go-raptorq/internal/impl/r10"
+import "github.com/harmony-one/go-raptorq/internal/impl/rlc"
+import "github.com/harmony-one/go-raptorq/internal/impl/rs"
+import "github.com/harmony-one/go-raptorq/pkg/selector"
+
+// DefaultEncoderFactory is the default encoder factory, that of the codec
+// returned by Codec.  It panics if there is no default codec.
 func DefaultEncoderFactory() raptorq.EncoderFactory {
-	return &libraptorq.EncoderFactory{}
+	return Codec().EncoderFactory
 }
 
-// DefaultDecoderFactory is the default decoder factory.
+// DefaultDecoderFactory is the default decoder factory, that of the codec
+// returned by Codec.  It panics if there is no default codec.
 func DefaultDecoderFactory() raptorq.DecoderFactory {
-	return &libraptorq.DecoderFactory{}
+	return Codec().DecoderFactory
 }
 
 // NewEncoder creates and returns an encoder using the default factory.
+//
+// NewEncoder returns an error if there is no default codec; see LookupCodec.
 func NewEncoder(
 	input []byte, symbolSize uint16, minSubSymbolSize uint16,
 	maxSubBlockSize uint32, alignment uint8,
 ) (enc raptorq.Encoder, err error) {
-	factory := DefaultEncoderFactory()
-	return factory.New(
+	codec, err := LookupCodec()
+	if err != nil {
+		return
+	}
+	return codec.EncoderFactory.New(
 		input, symbolSize, minSubSymbolSize, maxSubBlockSize, alignment,
 	)
 }
 
 // NewDecoder creates and returns a decoder using the default factory.
+//
+// NewDecoder returns an error if there is no default codec; see LookupCodec.
 func NewDecoder(commonOTI uint64, schemeSpecificOTI uint32) (
 	dec raptorq.Decoder, err error,
 ) {
-	factory := DefaultDecoderFactory()
-	return factory.New(commonOTI, schemeSpecificOTI)
+	codec, err := LookupCodec()
+	if err != nil {
+		return
+	}
+	return codec.DecoderFactory.New(commonOTI, schemeSpecificOTI)
+}
+
+// DefaultStreamEncoderFactory is the default sliding-window encoder factory.
+func DefaultStreamEncoderFactory() raptorq.StreamEncoderFactory {
+	return &rlc.EncoderFactory{}
+}
+
+// DefaultStreamDecoderFactory is the default sliding-window decoder factory.
+func DefaultStreamDecoderFactory() raptorq.StreamDecoderFactory {
+	return &rlc.DecoderFactory{}
+}
+
+// ReedSolomonEncoderFactory is the encoder factory of the Reed-Solomon code
+// over GF(2^8), for source objects of a handful of symbols.
+func ReedSolomonEncoderFactory() raptorq.EncoderFactory {
+	return &rs.EncoderFactory{}
+}
+
+// ReedSolomonDecoderFactory is the decoder factory of the Reed-Solomon code
+// over GF(2^8).
+func ReedSolomonDecoderFactory() raptorq.DecoderFactory {
+	return &rs.DecoderFactory{}
+}
+
+// SelectingEncoderFactory returns an encoder factory that uses the
+// Reed-Solomon code for source objects of at most
+// selector.DefaultMaxSmallSymbols source symbols, and the default codec
+// otherwise.
+//
+// SelectingEncoderFactory returns an error if there is no default codec; see
+// LookupCodec.
+func SelectingEncoderFactory() (f raptorq.EncoderFactory, err error) {
+	codec, err := LookupCodec()
+	if err != nil {
+		return
+	}
+	f = &selector.EncoderFactory{
+		Small: ReedSolomonEncoderFactory(),
+		Large: codec.EncoderFactory,
+	}
+	return
+}
+
+// SelectingDecoderFactory returns the decoder factory matching
+// SelectingEncoderFactory.
+//
+// SelectingDecoderFactory returns an error if there is no default codec; see
+// LookupCodec.
+func SelectingDecoderFactory() (f raptorq.DecoderFactory, err error) {
+	codec, err := LookupCodec()
+	if err != nil {
+		return
+	}
+	f = &selector.DecoderFactory{
+		Small: ReedSolomonDecoderFactory(),
+		Large: codec.DecoderFactory,
+	}
+	return
 }
@@ -0,0 +1,121 @@
+// Package esialloc partitions the encoding symbol ID (ESI) space of a source
+// block among multiple senders of the same source object, so that receivers
+// combining encoding symbols from all of them never see duplicates.
+//
+// All senders must use identical object transmission information, so that
+// the same (sbn, esi) pair identifies the same encoding symbol regardless of
+// which sender generated it.
+package esialloc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sort"
+)
+
+// Allocation is the share of the ESI space allocated to one sender.
+//
+// The share consists of every Count-th ESI starting at First+Index, that is,
+// First+Index, First+Index+Count, First+Index+2·Count, and so on.  ESIs
+// below First are not allocated to anyone; set First to the number of source
+// symbols in order to hand out repair symbols only.
+type Allocation struct {
+	// Index is the zero-based index of the sender.
+	Index uint32
+
+	// Count is the number of senders.
+	Count uint32
+
+	// First is the first ESI allocated.
+	First uint32
+}
+
+// ByIndex returns the allocation for the sender at the given index out of
+// count senders.
+func ByIndex(index, count uint32) (a Allocation, err error) {
+	if index >= count {
+		err = errors.New("sender index out of range")
+		return
+	}
+	a = Allocation{Index: index, Count: count}
+	return
+}
+
+// ByNodeID returns the allocation for the sender with the given node ID,
+// among all senders with the given node IDs (which should include self).
+//
+// Senders are ordered by the SHA-256 hash of their node IDs, so all senders
+// derive the same, evenly shuffled order from the same set of node IDs
+// regardless of the order in which each lists them.
+func ByNodeID(self []byte, all [][]byte) (a Allocation, err error) {
+	hashes := make([][]byte, 0, len(all))
+	seen := make(map[string]bool, len(all))
+	for _, id := range all {
+		hash := sha256.Sum256(id)
+		if seen[string(hash[:])] {
+			continue
+		}
+		seen[string(hash[:])] = true
+		hashes = append(hashes, hash[:])
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i], hashes[j]) < 0
+	})
+	selfHash := sha256.Sum256(self)
+	for i, hash := range hashes {
+		if bytes.Equal(hash, selfHash[:]) {
+			return ByIndex(uint32(i), uint32(len(hashes)))
+		}
+	}
+	err = errors.New("own node ID not among the senders")
+	return
+}
+
+// StartingAt returns a copy of the allocation that starts at the given ESI.
+func (a Allocation) StartingAt(first uint32) Allocation {
+	a.First = first
+	return a
+}
+
+// ESI returns the n-th (zero-based) ESI in the allocation, within a source
+// block from which up to maxSymbols encoding symbols can be generated (see
+// raptorq.Encoder.MaxSymbols).  It returns ok = false if the allocation has
+// fewer than n+1 ESIs.
+func (a Allocation) ESI(n uint32, maxSymbols uint32) (esi uint32, ok bool) {
+	if a.Count == 0 {
+		return
+	}
+	esi64 := uint64(a.First) + uint64(a.Index) + uint64(n)*uint64(a.Count)
+	if esi64 >= uint64(maxSymbols) {
+		return
+	}
+	return uint32(esi64), true
+}
+
+// Owns returns whether the given ESI is in the allocation.
+func (a Allocation) Owns(esi uint32) bool {
+	return a.Count != 0 && esi >= a.First &&
+		(esi-a.First)%a.Count == a.Index
+}
+
+// Len returns the number of ESIs in the allocation, within a source block
+// from which up to maxSymbols encoding symbols can be generated.
+func (a Allocation) Len(maxSymbols uint32) uint32 {
+	start := uint64(a.First) + uint64(a.Index)
+	if a.Count == 0 || start >= uint64(maxSymbols) {
+		return 0
+	}
+	return uint32((uint64(maxSymbols) - start + uint64(a.Count) - 1) /
+		uint64(a.Count))
+}
+
+// RepairESIs returns a function, suitable for relay.Config, that allocates
+// ESIs from the allocation regardless of source block.
+func (a Allocation) RepairESIs() func(sbn uint8, n uint32, maxSymbols uint32) (
+	esi uint32, ok bool,
+) {
+	return func(sbn uint8, n uint32, maxSymbols uint32) (uint32, bool) {
+		return a.ESI(n, maxSymbols)
+	}
+}
@@ -0,0 +1,38 @@
+package esialloc
+
+import "testing"
+
+func TestAllocationsAreDisjoint(t *testing.T) {
+	ids := [][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}
+	const maxSymbols = 1000
+	owner := make(map[uint32][]byte)
+	total := uint32(0)
+	for _, id := range ids {
+		a, err := ByNodeID(id, [][]byte{ids[2], ids[0], ids[1]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		a = a.StartingAt(10)
+		total += a.Len(maxSymbols)
+		for n := uint32(0); ; n++ {
+			esi, ok := a.ESI(n, maxSymbols)
+			if !ok {
+				if n != a.Len(maxSymbols) {
+					t.Errorf("%s: %d ESIs, Len() = %d", id, n,
+						a.Len(maxSymbols))
+				}
+				break
+			}
+			if !a.Owns(esi) {
+				t.Errorf("%s: allocated ESI %d but does not own it", id, esi)
+			}
+			if other, ok := owner[esi]; ok {
+				t.Fatalf("ESI %d allocated to both %s and %s", esi, other, id)
+			}
+			owner[esi] = id
+		}
+	}
+	if total != maxSymbols-10 {
+		t.Errorf("allocated %d ESIs, want %d", total, maxSymbols-10)
+	}
+}
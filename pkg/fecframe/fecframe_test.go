@@ -0,0 +1,202 @@
+package fecframe
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+// raptorQCodec returns the RaptorQ codec the tests run with, skipping them in
+// builds that have none.
+func raptorQCodec(t *testing.T) registry.Codec {
+	codec, err := defaults.RaptorQCodec()
+	if err != nil {
+		t.Skip(err)
+	}
+	return codec
+}
+
+type adu struct {
+	flowID uint8
+	data   string
+}
+
+func TestRecovery(t *testing.T) {
+	codec := raptorQCodec(t)
+	rng := rand.New(rand.NewSource(1))
+	cfg := Config{SymbolSize: 64, MaxSourceSymbols: 40}
+	var sent, received []adu
+	type sourcePacket struct {
+		flowID uint8
+		data   []byte
+	}
+	var sources []sourcePacket
+	var repairs [][]byte
+	s, err := NewSender(func(flowID uint8, packet []byte) error {
+		sources = append(sources,
+			sourcePacket{flowID, append([]byte(nil), packet...)})
+		return nil
+	}, func(packet []byte) error {
+		repairs = append(repairs, append([]byte(nil), packet...))
+		return nil
+	}, SenderConfig{
+		Config:     cfg,
+		Factory:    codec.EncoderFactory,
+		Redundancy: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		a := adu{uint8(i % 3), fmt.Sprintf("message %d %s", i,
+			bytes.Repeat([]byte{'x'}, rng.Intn(200)))}
+		sent = append(sent, a)
+		if err = s.Send(a.flowID, []byte(a.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	recovered := make(map[adu]bool)
+	r, err := NewReceiver(ReceiverConfig{
+		Config:    cfg,
+		Factory:   codec.DecoderFactory,
+		MaxBlocks: 1 << 10,
+		Deliver: func(flowID uint8, data []byte) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			recovered[adu{flowID, string(data)}] = true
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	lost := make(map[adu]bool)
+	for i, p := range sources {
+		if i%7 == 3 {
+			lost[sent[i]] = true
+			continue
+		}
+		data, err := r.HandleSourcePacket(p.flowID, p.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received = append(received, adu{p.flowID, string(data)})
+	}
+	for _, a := range received {
+		if lost[a] {
+			t.Fatalf("lost ADU %v received", a)
+		}
+	}
+	for _, p := range repairs {
+		if err := r.HandleRepairPacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		mutex.Lock()
+		n := len(recovered)
+		mutex.Unlock()
+		if n >= len(lost) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.Flush()
+	mutex.Lock()
+	defer mutex.Unlock()
+	for a := range lost {
+		if !recovered[a] {
+			t.Errorf("lost ADU %v not recovered", a)
+		}
+	}
+	if len(recovered) != len(lost) {
+		t.Errorf("recovered %d ADUs, want %d", len(recovered), len(lost))
+	}
+}
+
+func TestPartialRecovery(t *testing.T) {
+	codec := raptorQCodec(t)
+	cfg := Config{SymbolSize: 16, MaxSourceSymbols: 100}
+	var repairs [][]byte
+	s, err := NewSender(func(flowID uint8, packet []byte) error {
+		return nil
+	}, func(packet []byte) error {
+		repairs = append(repairs, append([]byte(nil), packet...))
+		return nil
+	}, SenderConfig{
+		Config:     cfg,
+		Factory:    codec.EncoderFactory,
+		Redundancy: 0.01,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A short ADU, which a single repair symbol can stand in for, and a
+	// long one, which it cannot.
+	for _, data := range []string{"short", string(make([]byte, 100))} {
+		if err = s.Send(7, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(repairs) != 1 {
+		t.Fatalf("%d repair packets, want 1", len(repairs))
+	}
+	var got []string
+	r, err := NewReceiver(ReceiverConfig{
+		Config:  cfg,
+		Factory: codec.DecoderFactory,
+		Deliver: func(flowID uint8, data []byte) {
+			got = append(got, string(data))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// Lose both ADUs; the source block cannot be recovered as a whole, so
+	// only ADUs whose source symbols the decoder still recovers may be
+	// delivered, and never garbage.
+	if err = r.HandleRepairPacket(repairs[0]); err != nil {
+		t.Fatal(err)
+	}
+	r.Flush()
+	if len(got) > 1 || len(got) == 1 && got[0] != "short" {
+		t.Errorf("recovered %q", got)
+	}
+}
+
+func TestOtherSchemes(t *testing.T) {
+	cfg := Config{SymbolSize: 16}
+	send := func(packet []byte) error { return nil }
+	_, err := NewSender(func(flowID uint8, packet []byte) error {
+		return nil
+	}, send, SenderConfig{
+		Config:  cfg,
+		Factory: defaults.ReedSolomonEncoderFactory(),
+	})
+	if err == nil {
+		t.Error("Reed-Solomon encoder factory accepted")
+	}
+	_, err = NewReceiver(ReceiverConfig{
+		Config:  cfg,
+		Factory: defaults.ReedSolomonDecoderFactory(),
+	})
+	if err == nil {
+		t.Error("Reed-Solomon decoder factory accepted")
+	}
+}
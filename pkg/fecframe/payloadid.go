@@ -0,0 +1,65 @@
+package fecframe
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// SourcePayloadIDSize is the size of the Explicit Source FEC Payload ID, in
+// octets.
+const SourcePayloadIDSize = 3
+
+// RepairPayloadIDSize is the size of the Repair FEC Payload ID, in octets.
+const RepairPayloadIDSize = 6
+
+// maxRepairESI is the largest ESI that fits in a Repair FEC Payload ID.
+const maxRepairESI = 1<<24 - 1
+
+// putSourcePayloadID writes the Explicit Source FEC Payload ID:
+//
+//	Source Block Number (SBN)          (8 bits)
+//	Encoding Symbol ID (ESI)           (16 bits)
+func putSourcePayloadID(b []byte, sbn uint8, esi uint16) {
+	b[0] = sbn
+	binary.BigEndian.PutUint16(b[1:], esi)
+}
+
+func parseSourcePayloadID(b []byte) (sbn uint8, esi uint16, err error) {
+	if len(b) < SourcePayloadIDSize {
+		err = errors.New("source FEC Payload ID too short")
+		return
+	}
+	return b[0], binary.BigEndian.Uint16(b[1:]), nil
+}
+
+// putRepairPayloadID writes the Repair FEC Payload ID:
+//
+//	Source Block Number (SBN)          (8 bits)
+//	Encoding Symbol ID (ESI)           (24 bits)
+//	Source Block Length (K)            (16 bits)
+func putRepairPayloadID(b []byte, sbn uint8, esi uint32, k uint16) {
+	binary.BigEndian.PutUint32(b, uint32(sbn)<<24|esi&maxRepairESI)
+	binary.BigEndian.PutUint16(b[4:], k)
+}
+
+func parseRepairPayloadID(b []byte) (sbn uint8, esi uint32, k uint16, err error) {
+	if len(b) < RepairPayloadIDSize {
+		err = errors.New("repair FEC Payload ID too short")
+		return
+	}
+	v := binary.BigEndian.Uint32(b)
+	return uint8(v >> 24), v & maxRepairESI, binary.BigEndian.Uint16(b[4:]), nil
+}
+
+// aduiHeaderSize is the size of the flow ID and length fields of an ADUI.
+const aduiHeaderSize = 3
+
+// MaxADUSize is the size of the largest ADU, whose length must fit in the
+// 16-bit length field of its ADUI.
+const MaxADUSize = 1<<16 - 1
+
+// numADUISymbols returns the number of source symbols an ADUI of an ADU of the
+// given size spans.
+func numADUISymbols(aduSize int, symbolSize uint16) int {
+	return (aduiHeaderSize + aduSize + int(symbolSize) - 1) / int(symbolSize)
+}
@@ -0,0 +1,376 @@
+package fecframe
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+// DefaultMaxBlocks is the default number of source blocks a receiver keeps
+// track of.
+const DefaultMaxBlocks = 4
+
+// DeliverFunc is called with each ADU recovered from repair packets.
+type DeliverFunc func(flowID uint8, adu []byte)
+
+// ReceiverConfig is the receiver configuration.
+type ReceiverConfig struct {
+	Config
+
+	// Factory creates the decoders, which must be RaptorQ ones.  If nil,
+	// the factory of defaults.RaptorQCodec is used.
+	Factory raptorq.DecoderFactory
+
+	// Deliver is called with each ADU recovered from repair packets, that
+	// is, one whose source packet was not received.
+	Deliver DeliverFunc
+
+	// MaxBlocks is the number of source blocks kept track of.  When a
+	// packet of a new source block arrives, the oldest source block beyond
+	// this number is given up, after recovering what ADUs it can.  If zero,
+	// DefaultMaxBlocks is used.
+	MaxBlocks int
+}
+
+// block is the receiver state of one source block.
+type block struct {
+	sbn     uint8
+	k       uint16
+	dec     raptorq.Decoder
+	symbols map[uint16][]byte
+
+	// spans maps the first ESI of each ADUI either received or recovered to
+	// the number of source symbols it spans.
+	spans map[uint16]uint16
+	done  bool
+}
+
+// recovered is an ADU recovered from repair packets.
+type recovered struct {
+	flowID uint8
+	adu    []byte
+}
+
+// Receiver recovers lost ADUs of a flow protected by a Sender.
+type Receiver struct {
+	cfg    ReceiverConfig
+	mutex  sync.Mutex
+	blocks []*block
+	closed bool
+}
+
+// NewReceiver returns a new receiver.
+func NewReceiver(cfg ReceiverConfig) (r *Receiver, err error) {
+	if cfg.SymbolSize == 0 {
+		err = errors.New("symbol size required")
+		return
+	}
+	if cfg.MaxSourceSymbols == 0 {
+		cfg.MaxSourceSymbols = DefaultMaxSourceSymbols
+	}
+	if cfg.Factory == nil {
+		var codec registry.Codec
+		if codec, err = defaults.RaptorQCodec(); err != nil {
+			return
+		}
+		cfg.Factory = codec.DecoderFactory
+	}
+	if cfg.MaxBlocks == 0 {
+		cfg.MaxBlocks = DefaultMaxBlocks
+	}
+	// Reject other FEC schemes up front, rather than at the first repair
+	// packet.
+	dec, err := cfg.Factory.New(blockOTI(1, cfg.SymbolSize))
+	if err != nil {
+		err = errors.New("decoder is not a RaptorQ one: " + err.Error())
+		return
+	}
+	_ = dec.Close()
+	r = &Receiver{cfg: cfg}
+	return
+}
+
+// HandleSourcePacket handles one source packet of the given flow, and returns
+// its ADU, which the caller passes on to the application right away.
+func (r *Receiver) HandleSourcePacket(flowID uint8, packet []byte) (
+	adu []byte, err error,
+) {
+	n := len(packet) - SourcePayloadIDSize
+	if n < 0 {
+		err = errors.New("source packet too short")
+		return
+	}
+	sbn, esi, err := parseSourcePayloadID(packet[n:])
+	if err != nil {
+		return
+	}
+	adu = packet[:n]
+	symbolSize := r.cfg.SymbolSize
+	span := numADUISymbols(len(adu), symbolSize)
+	if span > int(r.cfg.MaxSourceSymbols) ||
+		int(esi)+span > int(r.cfg.MaxSourceSymbols) {
+		err = errors.New("ADUI out of source block bounds")
+		return
+	}
+	adui := make([]byte, span*int(symbolSize))
+	adui[0] = flowID
+	binary.BigEndian.PutUint16(adui[1:], uint16(len(adu)))
+	copy(adui[aduiHeaderSize:], adu)
+	var deliver []recovered
+	r.mutex.Lock()
+	b, err := r.block(sbn, &deliver)
+	if err == nil && !b.done {
+		b.spans[esi] = uint16(span)
+		for i := 0; i < span; i++ {
+			symbol := adui[i*int(symbolSize) : (i+1)*int(symbolSize)]
+			r.addSymbol(b, esi+uint16(i), symbol)
+		}
+	}
+	r.mutex.Unlock()
+	r.deliver(deliver)
+	return
+}
+
+// HandleRepairPacket handles one repair packet.
+func (r *Receiver) HandleRepairPacket(packet []byte) (err error) {
+	sbn, esi, k, err := parseRepairPayloadID(packet)
+	if err != nil {
+		return
+	}
+	symbol := packet[RepairPayloadIDSize:]
+	switch {
+	case len(symbol) != int(r.cfg.SymbolSize):
+		return errors.New("repair symbol size mismatch")
+	case k == 0 || k > r.cfg.MaxSourceSymbols:
+		return errors.New("source block length out of range")
+	case esi < uint32(k):
+		return errors.New("repair packet with a source ESI")
+	}
+	var deliver []recovered
+	r.mutex.Lock()
+	b, err := r.block(sbn, &deliver)
+	if err == nil && !b.done {
+		err = r.startDecoder(b, k)
+		if err == nil {
+			b.dec.Decode(0, esi, symbol)
+		}
+	}
+	r.mutex.Unlock()
+	r.deliver(deliver)
+	return
+}
+
+// block returns the state of the given source block, creating one if needed
+// and giving up the oldest source blocks beyond the limit.  ADUs recovered
+// from given up source blocks are appended to deliver.
+//
+// The caller must hold the mutex.
+func (r *Receiver) block(sbn uint8, deliver *[]recovered) (
+	b *block, err error,
+) {
+	if r.closed {
+		err = errors.New("receiver closed")
+		return
+	}
+	for _, b = range r.blocks {
+		if b.sbn == sbn {
+			return
+		}
+	}
+	b = &block{
+		sbn:     sbn,
+		symbols: make(map[uint16][]byte),
+		spans:   make(map[uint16]uint16),
+	}
+	r.blocks = append(r.blocks, b)
+	for len(r.blocks) > r.cfg.MaxBlocks {
+		old := r.blocks[0]
+		r.blocks = r.blocks[1:]
+		r.finish(old, deliver)
+	}
+	return
+}
+
+// addSymbol adds a received source symbol to the given source block.
+//
+// The caller must hold the mutex.
+func (r *Receiver) addSymbol(b *block, esi uint16, symbol []byte) {
+	if _, ok := b.symbols[esi]; ok {
+		return
+	}
+	b.symbols[esi] = symbol
+	if b.dec != nil && esi < b.k {
+		b.dec.Decode(0, uint32(esi), symbol)
+	}
+}
+
+// startDecoder creates the decoder of the given source block, now that its
+// length is known, and feeds it the source symbols received so far.
+//
+// The caller must hold the mutex.
+func (r *Receiver) startDecoder(b *block, k uint16) (err error) {
+	if b.dec != nil {
+		if k != b.k {
+			err = errors.New("source block length mismatch")
+		}
+		return
+	}
+	dec, err := r.cfg.Factory.New(blockOTI(k, r.cfg.SymbolSize))
+	if err != nil {
+		return
+	}
+	ready := make(chan uint8, 1)
+	if err = dec.AddReadyBlockChan(ready); err != nil {
+		_ = dec.Close()
+		return
+	}
+	b.k, b.dec = k, dec
+	for esi, symbol := range b.symbols {
+		if esi < k {
+			dec.Decode(0, uint32(esi), symbol)
+		}
+	}
+	go r.awaitBlock(b, dec, ready)
+	return
+}
+
+// awaitBlock waits for the given source block to be recovered, then delivers
+// the ADUs whose source packets were not received.
+func (r *Receiver) awaitBlock(
+	b *block, dec raptorq.Decoder, ready <-chan uint8,
+) {
+	if _, ok := <-ready; !ok {
+		// The decoder has been closed.
+		return
+	}
+	var deliver []recovered
+	r.mutex.Lock()
+	if b.dec == dec && !b.done {
+		r.finish(b, &deliver)
+	}
+	r.mutex.Unlock()
+	r.deliver(deliver)
+}
+
+// finish recovers what ADUs it can from the given source block, appending
+// them to deliver, and releases its decoder.
+//
+// The caller must hold the mutex.
+func (r *Receiver) finish(b *block, deliver *[]recovered) {
+	if b.done {
+		return
+	}
+	b.done = true
+	if b.dec == nil {
+		// No repair packets, so nothing to recover.
+		return
+	}
+	if !b.dec.IsSourceBlockReady(0) {
+		// Let the decoder recover what source symbols it can.
+		_, _ = b.dec.EndOfInput(0, false)
+	}
+	buf := make([]byte, r.cfg.SymbolSize)
+	symbol := func(esi uint16) []byte {
+		if s, ok := b.symbols[esi]; ok {
+			return s
+		}
+		if _, err := b.dec.SourceSymbol(0, uint32(esi), buf); err != nil {
+			return nil
+		}
+		return append([]byte(nil), buf...)
+	}
+	for esi := uint16(0); esi < b.k; {
+		if span, ok := b.spans[esi]; ok {
+			esi += span
+			continue
+		}
+		first := symbol(esi)
+		if first == nil {
+			// The ADUI boundary is lost; resume at the next known one.
+			esi = r.nextSpan(b, esi)
+			continue
+		}
+		size := int(binary.BigEndian.Uint16(first[1:]))
+		span := numADUISymbols(size, r.cfg.SymbolSize)
+		if int(esi)+span > int(b.k) {
+			esi = r.nextSpan(b, esi)
+			continue
+		}
+		adui := append([]byte(nil), first...)
+		for i := 1; i < span && adui != nil; i++ {
+			if s := symbol(esi + uint16(i)); s != nil {
+				adui = append(adui, s...)
+			} else {
+				adui = nil
+			}
+		}
+		if adui != nil {
+			*deliver = append(*deliver, recovered{
+				flowID: adui[0],
+				adu:    adui[aduiHeaderSize : aduiHeaderSize+size],
+			})
+		}
+		b.spans[esi] = uint16(span)
+		esi += uint16(span)
+	}
+	_ = b.dec.Close()
+	b.dec = nil
+	b.symbols = nil
+}
+
+// nextSpan returns the first ESI after the given one that starts a known
+// ADUI, or the source block length if there is none.
+func (r *Receiver) nextSpan(b *block, esi uint16) uint16 {
+	next := b.k
+	for start := range b.spans {
+		if start > esi && start < next {
+			next = start
+		}
+	}
+	return next
+}
+
+func (r *Receiver) deliver(deliver []recovered) {
+	if r.cfg.Deliver == nil {
+		return
+	}
+	for _, d := range deliver {
+		r.cfg.Deliver(d.flowID, d.adu)
+	}
+}
+
+// Flush gives up all source blocks being received, after recovering what
+// ADUs it can from them, e.g. at the end of the flow.
+func (r *Receiver) Flush() {
+	var deliver []recovered
+	r.mutex.Lock()
+	for _, b := range r.blocks {
+		r.finish(b, &deliver)
+	}
+	r.blocks = nil
+	r.mutex.Unlock()
+	r.deliver(deliver)
+}
+
+// Close closes the receiver, discarding all source blocks being received.
+func (r *Receiver) Close() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		err = errors.New("receiver already closed")
+		return
+	}
+	r.closed = true
+	for _, b := range r.blocks {
+		if b.dec != nil {
+			_ = b.dec.Close()
+			b.dec = nil
+		}
+	}
+	r.blocks = nil
+	return
+}
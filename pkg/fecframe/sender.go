@@ -0,0 +1,224 @@
+// Package fecframe protects continuous flows of application data units (ADUs)
+// with RaptorQ, in the manner of the FEC Framework (RFC 6363) and its Raptor
+// FEC schemes for arbitrary packet flows (RFC 6681).
+//
+// The sender lays out each ADU as an ADU Information (ADUI):
+//
+//	Flow ID (F)   (8 bits)
+//	Length (L)    (16 bits)
+//	ADU           (L octets)
+//	Padding       (zeros up to a symbol boundary)
+//
+// and appends ADUIs to the current source block, which is protected by repair
+// symbols once full.  Source packets carry the ADU as is, followed by the
+// Explicit Source FEC Payload ID naming the first source symbol of its ADUI:
+//
+//	ADU || SBN (8 bits) || ESI (16 bits)
+//
+// and repair packets carry one repair symbol, along with the source block
+// length K:
+//
+//	SBN (8 bits) || ESI (24 bits) || K (16 bits) || repair symbol
+//
+// Source packets are handed to the application right away; the receiver
+// recovers ADUs of lost source packets from repair packets, even when the
+// rest of the source block cannot be recovered.
+package fecframe
+
+import (
+	"errors"
+	"math"
+
+	"github.com/harmony-one/go-raptorq/internal/rfc6330"
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+// DefaultMaxSourceSymbols is the default maximum number of source symbols per
+// source block.
+const DefaultMaxSourceSymbols = 100
+
+// SourceFunc sends one source packet of the given flow.  It must not retain
+// the packet after returning.
+type SourceFunc func(flowID uint8, packet []byte) error
+
+// RepairFunc sends one repair packet.  It must not retain the packet after
+// returning.
+type RepairFunc func(packet []byte) error
+
+// Config is the FECFRAME configuration, which the sender and receivers must
+// agree on.
+type Config struct {
+	// SymbolSize is the encoding symbol size T, in octets.
+	SymbolSize uint16
+
+	// MaxSourceSymbols is the maximum number of source symbols per source
+	// block.  If zero, DefaultMaxSourceSymbols is used.
+	MaxSourceSymbols uint16
+}
+
+// SenderConfig is the sender configuration.
+type SenderConfig struct {
+	Config
+
+	// Factory creates the encoders, which must be RaptorQ ones.  If nil,
+	// the factory of defaults.RaptorQCodec is used.
+	Factory raptorq.EncoderFactory
+
+	// Redundancy is the ratio of repair symbols sent for each source block,
+	// relative to its number of source symbols.
+	Redundancy float64
+}
+
+// Sender protects a flow of ADUs.
+type Sender struct {
+	cfg        SenderConfig
+	sendSource SourceFunc
+	sendRepair RepairFunc
+	sbn        uint8
+	block      []byte
+	packet     []byte
+}
+
+// NewSender returns a new sender that sends source and repair packets using
+// the given functions.
+func NewSender(
+	sendSource SourceFunc, sendRepair RepairFunc, cfg SenderConfig,
+) (s *Sender, err error) {
+	if cfg.SymbolSize == 0 {
+		err = errors.New("symbol size required")
+		return
+	}
+	if cfg.MaxSourceSymbols == 0 {
+		cfg.MaxSourceSymbols = DefaultMaxSourceSymbols
+	}
+	if cfg.Factory == nil {
+		var codec registry.Codec
+		if codec, err = defaults.RaptorQCodec(); err != nil {
+			return
+		}
+		cfg.Factory = codec.EncoderFactory
+	}
+	// Reject other FEC schemes up front, rather than at the first Flush.
+	enc, err := newBlockEncoder(cfg.Factory, make([]byte, cfg.SymbolSize), 1,
+		cfg.SymbolSize)
+	if err != nil {
+		return
+	}
+	_ = enc.Close()
+	s = &Sender{cfg: cfg, sendSource: sendSource, sendRepair: sendRepair}
+	return
+}
+
+// Send sends the given ADU of the given flow as a source packet, and adds it
+// to the current source block.  If the ADU does not fit in the current source
+// block, Send first closes it, as Flush does.
+func (s *Sender) Send(flowID uint8, adu []byte) (err error) {
+	if len(adu) > MaxADUSize {
+		err = errors.New("ADU too large")
+		return
+	}
+	symbolSize := int(s.cfg.SymbolSize)
+	numSymbols := numADUISymbols(len(adu), s.cfg.SymbolSize)
+	maxSymbols := int(s.cfg.MaxSourceSymbols)
+	if numSymbols > maxSymbols {
+		err = errors.New("ADU does not fit in a source block")
+		return
+	}
+	if len(s.block)/symbolSize+numSymbols > maxSymbols {
+		if err = s.Flush(); err != nil {
+			return
+		}
+	}
+	esi := uint16(len(s.block) / symbolSize)
+	s.packet = append(append(s.packet[:0], adu...),
+		make([]byte, SourcePayloadIDSize)...)
+	putSourcePayloadID(s.packet[len(adu):], s.sbn, esi)
+	if err = s.sendSource(flowID, s.packet); err != nil {
+		return
+	}
+	s.block = append(s.block, flowID, uint8(len(adu)>>8), uint8(len(adu)))
+	s.block = append(s.block, adu...)
+	s.block = append(s.block,
+		make([]byte, numSymbols*symbolSize-aduiHeaderSize-len(adu))...)
+	return
+}
+
+// Flush closes the current source block, if not empty, sending its repair
+// packets, and starts the next source block.
+func (s *Sender) Flush() (err error) {
+	if len(s.block) == 0 {
+		return
+	}
+	k := uint16(len(s.block) / int(s.cfg.SymbolSize))
+	enc, err := newBlockEncoder(s.cfg.Factory, s.block, k, s.cfg.SymbolSize)
+	if err != nil {
+		return
+	}
+	defer enc.Close()
+	numRepair := uint32(math.Ceil(float64(k) * s.cfg.Redundancy))
+	s.packet = append(s.packet[:0],
+		make([]byte, RepairPayloadIDSize+int(s.cfg.SymbolSize))...)
+	for i := uint32(0); i < numRepair; i++ {
+		esi := uint32(k) + i
+		if esi > maxRepairESI || esi >= enc.MaxSymbols(0) {
+			break
+		}
+		putRepairPayloadID(s.packet, s.sbn, esi, k)
+		if _, err = enc.Encode(0, esi, s.packet[RepairPayloadIDSize:]); err != nil {
+			return
+		}
+		if err = s.sendRepair(s.packet); err != nil {
+			return
+		}
+	}
+	s.sbn++
+	s.block = s.block[:0]
+	return
+}
+
+// newBlockEncoder returns an encoder that encodes the given source block of k
+// source symbols as a single-block object without sub-blocking, so that its
+// ESIs are the source symbol indices within the block.
+//
+// newBlockEncoder returns an error unless the encoder advertises the RaptorQ
+// OTIs of blockOTI, which receivers rebuild from K alone.
+func newBlockEncoder(
+	factory raptorq.EncoderFactory, block []byte, k uint16, symbolSize uint16,
+) (enc raptorq.Encoder, err error) {
+	minSubSymbolSize, maxSubBlockSize, ok :=
+		rfc6330.SingleBlockParams(k, symbolSize, 1, 1)
+	if !ok {
+		err = errors.New("no sender parameters for source block")
+		return
+	}
+	enc, err = factory.New(block, symbolSize, minSubSymbolSize,
+		maxSubBlockSize, 1)
+	if err != nil {
+		return
+	}
+	commonOTI, schemeSpecificOTI := blockOTI(k, symbolSize)
+	switch {
+	case enc.CommonOTI() != commonOTI ||
+		enc.SchemeSpecificOTI() != schemeSpecificOTI:
+		err = errors.New("encoder is not a RaptorQ one")
+	case enc.NumSourceBlocks() != 1 || enc.NumSourceSymbols(0) != k:
+		err = errors.New("encoder split the source block")
+	}
+	if err != nil {
+		_ = enc.Close()
+		enc = nil
+	}
+	return
+}
+
+// blockOTI returns the RaptorQ object transmission information of a source
+// block of k source symbols, encoded as by newBlockEncoder: one source block
+// (Z = 1) of one sub-block (N = 1), with a symbol alignment of 1.
+func blockOTI(k uint16, symbolSize uint16) (
+	commonOTI uint64, schemeSpecificOTI uint32,
+) {
+	transferLength := uint64(k) * uint64(symbolSize)
+	return transferLength<<24 | uint64(symbolSize), 1<<24 | 1<<8 | 1
+}
@@ -0,0 +1,245 @@
+package feedback
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+	"github.com/harmony-one/go-raptorq/pkg/schedule"
+)
+
+// minDeliveryRatio bounds the estimated delivery ratio from below, so that a
+// report sent before most symbols in flight arrived does not blow up the
+// number of symbols sent.
+const minDeliveryRatio = 0.1
+
+// defaultMargin is the default Config.Margin.
+const defaultMargin = 2
+
+// Config is the controller configuration.
+type Config struct {
+	// InitialRedundancy is the ratio of extra encoding symbols sent for each
+	// source block before any feedback arrives, relative to its number of
+	// source symbols, as in schedule.Config.
+	InitialRedundancy float64
+
+	// Margin is the number of encoding symbols sent for each source block on
+	// top of those the latest feedback says are still needed.  Zero means 2.
+	Margin uint32
+}
+
+// blockState is the state of a source block in the controller.
+type blockState int
+
+const (
+	// blockSending is when the block has budget left.
+	blockSending blockState = iota
+
+	// blockWaiting is when the block has used up its budget, and awaits
+	// feedback.
+	blockWaiting
+
+	// blockFinished is when the block has been reported ready, or has run
+	// out of encoding symbols.
+	blockFinished
+)
+
+type block struct {
+	state    blockState
+	sent     uint32
+	budget   uint32
+	received uint32
+	ready    bool
+}
+
+// Controller is a schedule of encoding symbols whose per-block redundancy
+// follows receiver feedback.
+//
+// Each source block starts with a budget of encoding symbols, source symbols
+// first then repair symbols, given by Config.InitialRedundancy.  Upon a
+// Progress report, the controller estimates the delivery ratio of the source
+// block from the symbols sent and received, and sets its budget to what is
+// needed to reach Encoder.MinSymbols plus Config.Margin received symbols.
+// A source block reported ready is finished and no longer sent.
+//
+// While every unfinished source block awaits feedback having used up its
+// budget, the controller keeps sending extra repair symbols for them in turn,
+// so that a lost report does not stall the transfer.
+//
+// Controller implements schedule.Iterator, and is meant to be used with a
+// sender.Sender, with Done as its completion signal.
+type Controller struct {
+	id        receiver.ObjectID
+	enc       raptorq.Encoder
+	cfg       Config
+	mutex     sync.Mutex
+	blocks    []block
+	sbn       int
+	remaining int
+	ready     int
+	done      chan struct{}
+}
+
+// NewController returns a new controller for the given encoder, which sends
+// the source object identified by the given object ID.
+func NewController(
+	id receiver.ObjectID, enc raptorq.Encoder, cfg Config,
+) *Controller {
+	if cfg.Margin == 0 {
+		cfg.Margin = defaultMargin
+	}
+	c := &Controller{
+		id:     id,
+		enc:    enc,
+		cfg:    cfg,
+		blocks: make([]block, enc.NumSourceBlocks()),
+		done:   make(chan struct{}),
+	}
+	for sbn := range c.blocks {
+		k := float64(enc.NumSourceSymbols(uint8(sbn)))
+		c.blocks[sbn].budget =
+			uint32(math.Ceil(k * (1 + cfg.InitialRedundancy)))
+	}
+	c.remaining = len(c.blocks)
+	if c.remaining == 0 {
+		close(c.done)
+	}
+	return c
+}
+
+// Next returns the next encoding symbol to send, or ok = false if all source
+// blocks are finished.
+func (c *Controller) Next() (e schedule.Entry, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for c.remaining > 0 {
+		sbn := c.pick()
+		b := &c.blocks[sbn]
+		if b.sent >= c.enc.MaxSymbols(sbn) {
+			c.finish(sbn, false)
+			continue
+		}
+		e = schedule.Entry{SBN: sbn, ESI: b.sent}
+		b.sent++
+		if b.state == blockSending && b.sent >= b.budget {
+			b.state = blockWaiting
+		}
+		ok = true
+		return
+	}
+	return
+}
+
+// pick returns the next unfinished source block in a round-robin fashion,
+// preferring source blocks with budget left.
+//
+// The caller must hold the mutex, and there must be an unfinished block.
+func (c *Controller) pick() uint8 {
+	for _, want := range []blockState{blockSending, blockWaiting} {
+		for i := 0; i < len(c.blocks); i++ {
+			sbn := (c.sbn + i) % len(c.blocks)
+			if c.blocks[sbn].state == want {
+				c.sbn = (sbn + 1) % len(c.blocks)
+				return uint8(sbn)
+			}
+		}
+	}
+	panic("no unfinished source block")
+}
+
+// finish finishes the given source block, and counts it as ready if so.
+//
+// A source block that has run out of encoding symbols may still be reported
+// ready later on, so readiness is counted apart from being finished.
+//
+// The caller must hold the mutex.
+func (c *Controller) finish(sbn uint8, ready bool) {
+	b := &c.blocks[sbn]
+	if b.state != blockFinished {
+		b.state = blockFinished
+		c.remaining--
+	}
+	if ready && !b.ready {
+		b.ready = true
+		c.ready++
+		if c.ready == len(c.blocks) {
+			close(c.done)
+		}
+	}
+}
+
+// HandleMessage updates the controller with the given feedback message.
+//
+// HandleMessage returns an error if the message is about another source
+// object, or names a source block out of range.
+func (c *Controller) HandleMessage(m *Message) (err error) {
+	if m.ObjectID != c.id {
+		err = errors.New("feedback message for another source object")
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	switch m.Type {
+	case CompleteMessage:
+		for sbn := range c.blocks {
+			c.finish(uint8(sbn), true)
+		}
+	case ProgressMessage:
+		for _, p := range m.Blocks {
+			if int(p.SBN) >= len(c.blocks) {
+				err = errors.New("source block number out of range")
+				return
+			}
+			c.update(p)
+		}
+	default:
+		err = errors.New("unknown feedback message type")
+	}
+	return
+}
+
+// update updates the budget of a source block from its reported progress.
+//
+// The caller must hold the mutex.
+func (c *Controller) update(p BlockProgress) {
+	if p.Ready {
+		c.finish(p.SBN, true)
+		return
+	}
+	b := &c.blocks[p.SBN]
+	if b.state == blockFinished {
+		return
+	}
+	// Reports may arrive out of order; counts only ever grow.
+	if p.Received > b.received {
+		b.received = p.Received
+	}
+	target := uint32(c.enc.MinSymbols(p.SBN)) + c.cfg.Margin
+	needed := uint32(1)
+	if b.received < target {
+		needed = target - b.received
+	}
+	ratio := minDeliveryRatio
+	if b.sent > 0 {
+		ratio = math.Max(ratio,
+			math.Min(1, float64(b.received)/float64(b.sent)))
+	}
+	b.budget = b.sent + uint32(math.Ceil(float64(needed)/ratio))
+	b.state = blockSending
+}
+
+// IsBlockFinished returns whether the given source block is finished, that is,
+// no more encoding symbols are sent for it.
+func (c *Controller) IsBlockFinished(sbn uint8) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return int(sbn) >= len(c.blocks) || c.blocks[sbn].state == blockFinished
+}
+
+// Done returns a channel that is closed once receivers report that all source
+// blocks are ready.
+func (c *Controller) Done() <-chan struct{} {
+	return c.done
+}
@@ -0,0 +1,155 @@
+package feedback
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	for _, m := range []*Message{
+		{Type: CompleteMessage, ObjectID: 42},
+		{Type: ProgressMessage, ObjectID: 1<<64 - 1, Blocks: []BlockProgress{
+			{SBN: 0, Ready: true, Received: 130},
+			{SBN: 1, Received: 70000},
+		}},
+	} {
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m1 Message
+		if err = m1.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*m, m1) {
+			t.Errorf("round trip of %+v returned %+v", *m, m1)
+		}
+		if err = m1.UnmarshalBinary(data[:len(data)-1]); err == nil {
+			t.Errorf("truncated %v message parsed", m.Type)
+		}
+	}
+}
+
+func TestController(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	object := make([]byte, 100000)
+	rng.Read(object)
+	enc, err := defaults.ReedSolomonEncoderFactory().New(object,
+		1000, 1000, 30000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	dec, err := defaults.ReedSolomonDecoderFactory().New(enc.CommonOTI(),
+		enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+
+	const lossRate = 0.3
+	c := NewController(7, enc, Config{})
+	sent := 0
+	for round := 0; ; round++ {
+		if round > 1000 {
+			t.Fatal("controller did not complete")
+		}
+		select {
+		case <-c.Done():
+		default:
+			// Send a burst, then report, as a receiver would periodically.
+			for i := 0; i < 20; i++ {
+				e, ok := c.Next()
+				if !ok {
+					t.Fatal("controller ran out of symbols")
+				}
+				sent++
+				if rng.Float64() < lossRate {
+					continue
+				}
+				buf := make([]byte, enc.SymbolSize())
+				if _, err := enc.Encode(e.SBN, e.ESI, buf); err != nil {
+					t.Fatal(err)
+				}
+				dec.DecodeBatch([]raptorq.Symbol{
+					{SBN: e.SBN, ESI: e.ESI, Data: buf},
+				})
+			}
+			data, err := Report(7, dec).MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m Message
+			if err = m.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if err = c.HandleMessage(&m); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		break
+	}
+	if _, ok := c.Next(); ok {
+		t.Error("controller still sending after completion")
+	}
+	buf := make([]byte, dec.TransferLength())
+	if _, err := dec.SourceObject(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, object) {
+		t.Fatal("recovered object mismatch")
+	}
+	// A generous bound, only meant to catch runaway sending.
+	if limit := 4 * len(object) / int(enc.SymbolSize()); sent > limit {
+		t.Errorf("sent %d symbols, want at most %d", sent, limit)
+	}
+}
+
+func TestControllerReadyAfterExhausted(t *testing.T) {
+	object := make([]byte, 2000)
+	enc, err := defaults.ReedSolomonEncoderFactory().New(object,
+		100, 100, 1000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	c := NewController(7, enc, Config{InitialRedundancy: 1})
+	// Use up every encoding symbol of both source blocks.
+	for {
+		if _, ok := c.Next(); !ok {
+			break
+		}
+	}
+	if !c.IsBlockFinished(0) || !c.IsBlockFinished(1) {
+		t.Fatal("source blocks not finished once out of symbols")
+	}
+	select {
+	case <-c.Done():
+		t.Fatal("done without any report")
+	default:
+	}
+	err = c.HandleMessage(&Message{Type: ProgressMessage, ObjectID: 7,
+		Blocks: []BlockProgress{{SBN: 0, Ready: true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c.Done():
+		t.Fatal("done with source block 1 not reported ready")
+	default:
+	}
+	if err = c.HandleMessage(&Message{Type: CompleteMessage, ObjectID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Error("not done after the complete message")
+	}
+}
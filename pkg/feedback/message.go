@@ -0,0 +1,170 @@
+// Package feedback provides a feedback protocol through which receivers
+// report their decoding progress to the sender, and a sender-side controller
+// that adapts the redundancy of each source block to the feedback.
+package feedback
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+)
+
+// MessageType is the type of a feedback message.
+type MessageType uint8
+
+const (
+	// ProgressMessage reports the decoding progress of each source block.
+	ProgressMessage MessageType = 1
+
+	// CompleteMessage reports that the entire source object has been
+	// recovered.
+	CompleteMessage MessageType = 2
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case ProgressMessage:
+		return "Progress"
+	case CompleteMessage:
+		return "Complete"
+	default:
+		return fmt.Sprintf("MessageType(%d)", uint8(t))
+	}
+}
+
+// BlockProgress is the decoding progress of one source block.
+type BlockProgress struct {
+	// SBN is the source block number.
+	SBN uint8
+
+	// Ready is whether the source block has been recovered.
+	Ready bool
+
+	// Received is the number of encoding symbols the decoder has accepted
+	// for the source block.
+	Received uint32
+}
+
+// Message is a feedback message.  Its wire format is, in network byte order:
+//
+//	Type                     (8 bits)
+//	Object ID                (64 bits)
+//
+// followed, for a Progress message, by:
+//
+//	Number of blocks         (16 bits)
+//
+// and, for each block:
+//
+//	Source Block Number      (8 bits)
+//	Flags                    (8 bits; bit 0 = ready)
+//	Received                 (32 bits)
+type Message struct {
+	Type     MessageType
+	ObjectID receiver.ObjectID
+
+	// Blocks is the progress of each source block, for a Progress message.
+	Blocks []BlockProgress
+}
+
+const (
+	messageHeaderSize = 9
+	blockCountSize    = 2
+	blockEntrySize    = 6
+	readyFlag         = 0x01
+)
+
+// Report returns a feedback message describing the current state of the given
+// decoder: a Complete message if the source object has been recovered,
+// otherwise a Progress message for all its source blocks.
+func Report(id receiver.ObjectID, dec raptorq.Decoder) *Message {
+	if dec.IsSourceObjectReady() {
+		return &Message{Type: CompleteMessage, ObjectID: id}
+	}
+	m := &Message{Type: ProgressMessage, ObjectID: id}
+	numSourceBlocks := int(dec.NumSourceBlocks())
+	m.Blocks = make([]BlockProgress, numSourceBlocks)
+	for sbn := range m.Blocks {
+		m.Blocks[sbn] = BlockProgress{
+			SBN:      uint8(sbn),
+			Ready:    dec.IsSourceBlockReady(uint8(sbn)),
+			Received: dec.NumReceivedSymbols(uint8(sbn)),
+		}
+	}
+	return m
+}
+
+// MarshalBinary returns the message in its wire format.
+func (m *Message) MarshalBinary() (data []byte, err error) {
+	switch m.Type {
+	case CompleteMessage:
+		data = make([]byte, messageHeaderSize)
+	case ProgressMessage:
+		if len(m.Blocks) > 256 {
+			err = errors.New("too many source blocks in progress message")
+			return
+		}
+		data = make([]byte,
+			messageHeaderSize+blockCountSize+len(m.Blocks)*blockEntrySize)
+		binary.BigEndian.PutUint16(data[messageHeaderSize:],
+			uint16(len(m.Blocks)))
+		b := data[messageHeaderSize+blockCountSize:]
+		for _, block := range m.Blocks {
+			b[0] = block.SBN
+			if block.Ready {
+				b[1] = readyFlag
+			}
+			binary.BigEndian.PutUint32(b[2:], block.Received)
+			b = b[blockEntrySize:]
+		}
+	default:
+		err = fmt.Errorf("unknown feedback message type %d", uint8(m.Type))
+		return
+	}
+	data[0] = uint8(m.Type)
+	binary.BigEndian.PutUint64(data[1:], uint64(m.ObjectID))
+	return
+}
+
+// UnmarshalBinary parses a message in its wire format.
+func (m *Message) UnmarshalBinary(data []byte) (err error) {
+	if len(data) < messageHeaderSize {
+		err = errors.New("feedback message too short")
+		return
+	}
+	t := MessageType(data[0])
+	id := receiver.ObjectID(binary.BigEndian.Uint64(data[1:]))
+	var blocks []BlockProgress
+	switch t {
+	case CompleteMessage:
+	case ProgressMessage:
+		data = data[messageHeaderSize:]
+		if len(data) < blockCountSize {
+			err = errors.New("feedback message too short")
+			return
+		}
+		n := int(binary.BigEndian.Uint16(data))
+		data = data[blockCountSize:]
+		if len(data) < n*blockEntrySize {
+			err = errors.New("feedback message too short")
+			return
+		}
+		blocks = make([]BlockProgress, n)
+		for i := range blocks {
+			blocks[i] = BlockProgress{
+				SBN:      data[0],
+				Ready:    data[1]&readyFlag != 0,
+				Received: binary.BigEndian.Uint32(data[2:]),
+			}
+			data = data[blockEntrySize:]
+		}
+	default:
+		err = fmt.Errorf("unknown feedback message type %d", uint8(t))
+		return
+	}
+	m.Type, m.ObjectID, m.Blocks = t, id, blocks
+	return
+}
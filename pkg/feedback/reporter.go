@@ -0,0 +1,44 @@
+package feedback
+
+import (
+	"context"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+)
+
+// SendFunc sends one feedback message in its wire format.
+type SendFunc func(data []byte) error
+
+// RunReporter sends a report of the given decoder every interval, until the
+// source object is recovered, in which case it sends a Complete message and
+// returns nil, or until ctx is done.
+//
+// A Complete message may be lost like any other; receivers should answer
+// packets of a recovered source object with another Complete message.
+func RunReporter(
+	ctx context.Context, id receiver.ObjectID, dec raptorq.Decoder,
+	interval time.Duration, send SendFunc,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m := Report(id, dec)
+		data, err := m.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if err = send(data); err != nil {
+			return err
+		}
+		if m.Type == CompleteMessage {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
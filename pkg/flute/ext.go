@@ -0,0 +1,128 @@
+package flute
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Header extension types used by ALC and FLUTE.
+const (
+	// ExtFTI is EXT_FTI, the FEC Object Transmission Information of RFC
+	// 5775 Section 5.2.
+	ExtFTI = 64
+
+	// ExtFDT is EXT_FDT, the FDT Instance header extension of RFC 6726
+	// Section 3.4.1.
+	ExtFDT = 192
+)
+
+// FECEncodingID is the FEC Encoding ID of RaptorQ, RFC 6330 Section 3.1,
+// which ALC carries in the LCT codepoint.
+const FECEncodingID = 6
+
+// FDTVersion is the FLUTE version carried in EXT_FDT, which is 2 for RFC 6726.
+const FDTVersion = 2
+
+// MaxFDTInstanceID is the largest FDT Instance ID, which is 20 bits long.
+const MaxFDTInstanceID = 1<<20 - 1
+
+// ftiSize is the size of the EXT_FTI content for FEC Encoding ID 6: the
+// 12-octet encoded FEC OTI of RFC 6330 Section 3.3, followed by 2 octets of
+// padding to a 32-bit boundary.
+const ftiSize = 14
+
+// FTIExtension returns the EXT_FTI header extension carrying the given
+// RaptorQ OTIs:
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|   HET = 64    |    HEL = 4    |                               |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+                               +
+//	|                      Transfer Length (F)                      |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|   Reserved    |        Symbol Size (T)        |       Z       |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|              N                |      Al       |    Padding    |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|    Padding    |
+//	+-+-+-+-+-+-+-+-+
+//
+// commonOTI and schemeSpecificOTI are as returned by raptorq.ObjectInfo, so
+// the encoded FEC OTI is simply their concatenation.
+func FTIExtension(commonOTI uint64, schemeSpecificOTI uint32) HeaderExtension {
+	content := make([]byte, ftiSize)
+	binary.BigEndian.PutUint64(content[0:], commonOTI)
+	binary.BigEndian.PutUint32(content[8:], schemeSpecificOTI)
+	return HeaderExtension{Type: ExtFTI, Content: content}
+}
+
+// checkOTI returns an error unless the given OTIs are valid RaptorQ ones:
+// a zero reserved octet, and non-zero F, T, Z, N and Al, with T a multiple of
+// Al.  The OTIs of other FEC schemes, e.g. Reed-Solomon with its zero top
+// octet, fail the check.
+func checkOTI(commonOTI uint64, schemeSpecificOTI uint32) (err error) {
+	symbolSize := uint16(commonOTI)
+	z := uint8(schemeSpecificOTI >> 24)
+	n := uint16(schemeSpecificOTI >> 8)
+	al := uint8(schemeSpecificOTI)
+	switch {
+	case commonOTI>>24 == 0 || uint8(commonOTI>>16) != 0 || symbolSize == 0:
+		err = errors.New("not a RaptorQ common FEC OTI")
+	case z == 0 || n == 0 || al == 0 || symbolSize%uint16(al) != 0:
+		err = errors.New("not a RaptorQ scheme-specific FEC OTI")
+	}
+	return
+}
+
+// ParseFTI parses the RaptorQ OTIs out of the given EXT_FTI header extension.
+func ParseFTI(ext *HeaderExtension) (
+	commonOTI uint64, schemeSpecificOTI uint32, err error,
+) {
+	if ext.Type != ExtFTI {
+		err = fmt.Errorf("header extension %d is not EXT_FTI", ext.Type)
+		return
+	}
+	if len(ext.Content) < 12 {
+		err = errors.New("EXT_FTI too short")
+		return
+	}
+	commonOTI = binary.BigEndian.Uint64(ext.Content[0:])
+	schemeSpecificOTI = binary.BigEndian.Uint32(ext.Content[8:])
+	err = checkOTI(commonOTI, schemeSpecificOTI)
+	return
+}
+
+// FDTExtension returns the EXT_FDT header extension for the given FDT
+// Instance ID:
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|   HET = 192   |   V   |          FDT Instance ID              |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+func FDTExtension(instanceID uint32) HeaderExtension {
+	v := uint32(FDTVersion)<<20 | instanceID&MaxFDTInstanceID
+	return HeaderExtension{
+		Type:    ExtFDT,
+		Content: []byte{uint8(v >> 16), uint8(v >> 8), uint8(v)},
+	}
+}
+
+// ParseFDTExtension parses the FDT Instance ID out of the given EXT_FDT header
+// extension.
+func ParseFDTExtension(ext *HeaderExtension) (instanceID uint32, err error) {
+	if ext.Type != ExtFDT || len(ext.Content) != 3 {
+		err = fmt.Errorf("header extension %d is not EXT_FDT", ext.Type)
+		return
+	}
+	v := uint32(ext.Content[0])<<16 | uint32(ext.Content[1])<<8 |
+		uint32(ext.Content[2])
+	if version := v >> 20; version != FDTVersion {
+		err = fmt.Errorf("unsupported FLUTE version %d", version)
+		return
+	}
+	instanceID = v & MaxFDTInstanceID
+	return
+}
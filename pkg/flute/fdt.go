@@ -0,0 +1,93 @@
+package flute
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"time"
+)
+
+// FDTTOI is the TOI of FDT Instances.
+const FDTTOI = 0
+
+// ntpEpochOffset is the number of seconds from the NTP epoch (1900) to the
+// Unix epoch (1970).
+const ntpEpochOffset = 2208988800
+
+// FDTInstance is a minimal FDT Instance of RFC 6726 Section 3.4.2, describing
+// the files of a session.
+type FDTInstance struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:fdt FDT-Instance"`
+
+	// Expires is the expiry time, in seconds since the NTP epoch.
+	Expires uint64 `xml:"Expires,attr"`
+
+	Files []FDTFile `xml:"File"`
+}
+
+// FDTFile describes one file in an FDT Instance.
+type FDTFile struct {
+	TOI             uint64 `xml:"TOI,attr"`
+	ContentLocation string `xml:"Content-Location,attr"`
+	ContentLength   uint64 `xml:"Content-Length,attr,omitempty"`
+	ContentType     string `xml:"Content-Type,attr,omitempty"`
+
+	// The FEC OTI of the file, which receivers use when packets of the file
+	// do not carry EXT_FTI.
+	FECEncodingID        uint8  `xml:"FEC-OTI-FEC-Encoding-ID,attr,omitempty"`
+	TransferLength       uint64 `xml:"FEC-OTI-Transfer-Length,attr,omitempty"`
+	EncodingSymbolLength uint16 `xml:"FEC-OTI-Encoding-Symbol-Length,attr,omitempty"`
+	SchemeSpecificInfo   string `xml:"FEC-OTI-Scheme-Specific-Info,attr,omitempty"`
+}
+
+// NTPTime returns the given time in seconds since the NTP epoch, for
+// FDTInstance.Expires.
+func NTPTime(t time.Time) uint64 {
+	return uint64(t.Unix() + ntpEpochOffset)
+}
+
+// SetOTI sets the FEC OTI attributes of the file to the given RaptorQ OTIs.
+func (f *FDTFile) SetOTI(commonOTI uint64, schemeSpecificOTI uint32) {
+	f.FECEncodingID = FECEncodingID
+	f.TransferLength = commonOTI >> 24
+	f.EncodingSymbolLength = uint16(commonOTI)
+	var ssi [4]byte
+	binary.BigEndian.PutUint32(ssi[:], schemeSpecificOTI)
+	f.SchemeSpecificInfo = base64.StdEncoding.EncodeToString(ssi[:])
+}
+
+// OTI returns the RaptorQ OTIs from the FEC OTI attributes of the file.
+func (f *FDTFile) OTI() (commonOTI uint64, schemeSpecificOTI uint32, err error) {
+	if f.FECEncodingID != FECEncodingID {
+		err = errors.New("file not encoded with RaptorQ")
+		return
+	}
+	ssi, err := base64.StdEncoding.DecodeString(f.SchemeSpecificInfo)
+	if err != nil {
+		return
+	}
+	if len(ssi) != 4 {
+		err = errors.New("invalid RaptorQ scheme-specific information")
+		return
+	}
+	commonOTI = f.TransferLength<<24 | uint64(f.EncodingSymbolLength)
+	schemeSpecificOTI = binary.BigEndian.Uint32(ssi)
+	err = checkOTI(commonOTI, schemeSpecificOTI)
+	return
+}
+
+// MarshalBinary returns the FDT Instance as an XML document.
+func (fdt *FDTInstance) MarshalBinary() (data []byte, err error) {
+	data, err = xml.Marshal(fdt)
+	if err != nil {
+		return
+	}
+	data = append([]byte(xml.Header), data...)
+	return
+}
+
+// UnmarshalBinary parses an FDT Instance from an XML document.
+func (fdt *FDTInstance) UnmarshalBinary(data []byte) error {
+	return xml.Unmarshal(data, fdt)
+}
@@ -0,0 +1,267 @@
+package flute
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+func TestLCTHeaderRoundTrip(t *testing.T) {
+	for _, h := range []*LCTHeader{
+		{CCI: make([]byte, 4), TSI: 0, TOI: 0, Codepoint: FECEncodingID},
+		{CCI: []byte{1, 2, 3, 4, 5, 6, 7, 8}, PSI: 2, TSI: 0x1234,
+			TOI: 0x56789a, CloseObject: true},
+		{CCI: make([]byte, 4), TSI: MaxTSI, TOI: 1<<64 - 1,
+			CloseSession: true, Extensions: []HeaderExtension{
+				FDTExtension(12345), FTIExtension(100000<<24|1000, 3<<24|1<<8|1),
+			}},
+	} {
+		data, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, 0xaa)
+		h1, rest, err := ParseLCTHeader(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(h, h1) {
+			t.Errorf("round trip of %+v returned %+v", h, h1)
+		}
+		if !bytes.Equal(rest, []byte{0xaa}) {
+			t.Errorf("rest = %v, want [0xaa]", rest)
+		}
+	}
+}
+
+func TestExtensions(t *testing.T) {
+	id, err := ParseFDTExtension(&[]HeaderExtension{FDTExtension(0xabcde)}[0])
+	if err != nil || id != 0xabcde {
+		t.Errorf("FDT Instance ID = %#x, %v, want 0xabcde", id, err)
+	}
+	fti := FTIExtension(1<<63|1400, 0x01020304)
+	if size := fti.size(); size != 16 {
+		t.Errorf("EXT_FTI size = %d, want 16", size)
+	}
+	common, ss, err := ParseFTI(&fti)
+	if err != nil || common != 1<<63|1400 || ss != 0x01020304 {
+		t.Errorf("ParseFTI() = %#x, %#x, %v", common, ss, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	codec, err := defaults.RaptorQCodec()
+	if err != nil {
+		t.Skip(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	files := []File{
+		{Name: "file:///a.bin", ContentType: "application/octet-stream",
+			Data: make([]byte, 50000)},
+		{Name: "file:///b.txt", ContentType: "text/plain",
+			Data: []byte("hello, world\n")},
+	}
+	rng.Read(files[0].Data)
+
+	// An in-memory transport that drops packets at random, and delivers
+	// the rest out of order.
+	var packets [][]byte
+	s, err := NewSender(42, func(packet []byte) error {
+		if rng.Float64() >= 0.1 {
+			packets = append(packets, append([]byte(nil), packet...))
+		}
+		return nil
+	}, SenderConfig{
+		Factory:    codec.EncoderFactory,
+		SymbolSize: 5000,
+		Redundancy: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Send(context.Background(), files); err != nil {
+		t.Fatal(err)
+	}
+	rng.Shuffle(len(packets), func(i, j int) {
+		packets[i], packets[j] = packets[j], packets[i]
+	})
+
+	delivered := make(chan *File, len(files))
+	r, err := NewReceiver(42, ReceiverConfig{
+		Factory: codec.DecoderFactory,
+		Deliver: func(f *File) { delivered <- f },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, packet := range packets {
+		_ = r.HandlePacket(packet)
+	}
+	got := make(map[string]*File)
+	for range files {
+		select {
+		case f := <-delivered:
+			got[f.Name] = f
+		case <-time.After(10 * time.Second):
+			t.Fatal("files not delivered")
+		}
+	}
+	for _, want := range files {
+		f := got[want.Name]
+		if f == nil {
+			t.Errorf("%s not delivered", want.Name)
+			continue
+		}
+		if f.TOI != want.TOI || f.ContentType != want.ContentType ||
+			!bytes.Equal(f.Data, want.Data) {
+			t.Errorf("%s delivered as %+v", want.Name, f)
+		}
+	}
+	if err := r.HandlePacket(packets[0][:3]); err == nil {
+		t.Error("truncated packet accepted")
+	}
+	other, _ := NewReceiver(43, ReceiverConfig{
+		Factory: codec.DecoderFactory,
+	})
+	defer other.Close()
+	if err := other.HandlePacket(packets[0]); err == nil {
+		t.Error("packet of another session accepted")
+	}
+}
+
+func TestOtherSchemes(t *testing.T) {
+	if err := checkOTI(100000<<24|1000, 0<<24|50<<16|255<<8|8); err == nil {
+		t.Error("Reed-Solomon OTI accepted")
+	}
+	s, err := NewSender(42, func(packet []byte) error {
+		t.Error("packet sent")
+		return nil
+	}, SenderConfig{
+		Factory:    defaults.ReedSolomonEncoderFactory(),
+		SymbolSize: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Send(context.Background(), []File{
+		{Name: "file:///a.bin", Data: make([]byte, 1000)},
+	})
+	if err == nil {
+		t.Error("Reed-Solomon encoder accepted")
+	}
+
+	// A packet advertising a Reed-Solomon OTI in its EXT_FTI.
+	h := &LCTHeader{TSI: 42, TOI: 1, Codepoint: FECEncodingID,
+		Extensions: []HeaderExtension{
+			FTIExtension(1000<<24|100, 0<<24|10<<16|255<<8|8),
+		}}
+	packet, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet = append(packet, make([]byte, raptorq.FECPayloadIDSize+100)...)
+	r, err := NewReceiver(42, ReceiverConfig{
+		Factory: defaults.ReedSolomonDecoderFactory(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.HandlePacket(packet); err == nil {
+		t.Error("Reed-Solomon packet accepted")
+	}
+}
+
+func TestEvict(t *testing.T) {
+	r, err := NewReceiver(42, ReceiverConfig{
+		Factory:      defaults.ReedSolomonDecoderFactory(),
+		Timeout:      time.Minute,
+		MaxCompleted: 2,
+		MaxPending:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	now := time.Now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for toi := uint64(1); toi <= 3; toi++ {
+		r.remember(objectKey{toi: toi}, now)
+	}
+	if _, ok := r.completed[objectKey{toi: 1}]; ok || len(r.completed) != 2 {
+		t.Errorf("completed = %v, want TOIs 2 and 3", r.completed)
+	}
+	r.hold(objectKey{toi: 2}, []byte{2}, now)
+	r.hold(objectKey{toi: 3}, []byte{3}, now)
+	if _, ok := r.pending[3]; !ok || len(r.pending) != 1 {
+		t.Errorf("pending = %v, want TOI 3", r.pending)
+	}
+	r.evict(now.Add(time.Minute))
+	if len(r.completed) != 0 || len(r.pending) != 0 {
+		t.Errorf("completed = %v, pending = %v after timeout",
+			r.completed, r.pending)
+	}
+	if len(r.completedOrder) != 0 || len(r.pendingOrder) != 0 {
+		t.Errorf("completedOrder = %v, pendingOrder = %v after timeout",
+			r.completedOrder, r.pendingOrder)
+	}
+}
+
+func TestEvictDecoders(t *testing.T) {
+	r, err := NewReceiver(42, ReceiverConfig{
+		Factory:   defaults.ReedSolomonDecoderFactory(),
+		Timeout:   time.Minute,
+		MaxActive: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	enc, err := defaults.ReedSolomonEncoderFactory().New(make([]byte, 100),
+		10, 10, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	now := time.Now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	decs := make([]raptorq.Decoder, 4)
+	for i := range decs {
+		if decs[i], err = defaults.ReedSolomonDecoderFactory().New(
+			enc.CommonOTI(), enc.SchemeSpecificOTI()); err != nil {
+			t.Fatal(err)
+		}
+		at := now.Add(time.Duration(i) * time.Second)
+		if err = r.activate(objectKey{toi: uint64(i)}, decs[i], at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Beyond MaxActive, the objects least recently heard from go first.
+	if len(r.decoders) != 2 || r.decoders[objectKey{toi: 2}] == nil ||
+		r.decoders[objectKey{toi: 3}] == nil {
+		t.Errorf("decoders = %v, want TOIs 2 and 3", r.decoders)
+	}
+	for _, dec := range decs[:2] {
+		if dec.Close() == nil {
+			t.Error("evicted decoder not closed")
+		}
+	}
+	// Hearing from object 2 keeps it past the timeout of object 3.
+	r.decoders[objectKey{toi: 2}].at = now.Add(time.Minute)
+	r.evict(now.Add(3*time.Second + time.Minute))
+	if len(r.decoders) != 1 || r.decoders[objectKey{toi: 2}] == nil {
+		t.Errorf("decoders = %v after timeout, want TOI 2", r.decoders)
+	}
+	if decs[3].Close() == nil {
+		t.Error("timed out decoder not closed")
+	}
+}
@@ -0,0 +1,274 @@
+package flute
+
+import (
+	"errors"
+	"fmt"
+)
+
+// LCTVersion is the LCT version number used by ALC and FLUTE.
+const LCTVersion = 1
+
+// MaxTSI is the largest Transport Session Identifier, which is at most 48
+// bits long.
+const MaxTSI = 1<<48 - 1
+
+// HeaderExtension is an LCT header extension.
+//
+// For extension types below 128, Content is variable-length, and the header
+// extension length (HEL) is derived from it; len(Content)+2 must be a
+// multiple of 4.  For types 128 and above, Content is exactly 3 octets.
+type HeaderExtension struct {
+	Type    uint8
+	Content []byte
+}
+
+// size returns the size of the header extension, in octets.
+func (ext *HeaderExtension) size() int {
+	if ext.Type >= 128 {
+		return 4
+	}
+	return 2 + len(ext.Content)
+}
+
+// LCTHeader is the LCT header of RFC 5651 Section 5.1:
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|   V   | C |PSI|S| O |H|Res|A|B|   HDR_LEN     | Codepoint (CP)|
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	| Congestion Control Information (CCI, length = 32*(C+1) bits)  |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|  Transport Session Identifier (TSI, length = 32*S+16*H bits)  |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|   Transport Object Identifier (TOI, length = 32*O+16*H bits)  |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                Header Extensions (if applicable)              |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+// TOIs longer than 64 bits are not supported.  When marshaling, the TSI and
+// the TOI are given the shortest lengths that fit their values.
+type LCTHeader struct {
+	// CCI is the congestion control information, 4, 8, 12 or 16 octets
+	// long.  If empty, a 4-octet zero CCI is sent.
+	CCI []byte
+
+	// PSI is the protocol-specific indication, 2 bits.
+	PSI uint8
+
+	// TSI is the Transport Session Identifier.
+	TSI uint64
+
+	// TOI is the Transport Object Identifier.
+	TOI uint64
+
+	// CloseSession (A) signals the end of the session.
+	CloseSession bool
+
+	// CloseObject (B) signals the end of the object.
+	CloseObject bool
+
+	// Codepoint is the LCT codepoint, which ALC uses to carry the FEC
+	// Encoding ID.
+	Codepoint uint8
+
+	Extensions []HeaderExtension
+}
+
+// Extension returns the first header extension of the given type, or nil if
+// there is none.
+func (h *LCTHeader) Extension(het uint8) *HeaderExtension {
+	for i := range h.Extensions {
+		if h.Extensions[i].Type == het {
+			return &h.Extensions[i]
+		}
+	}
+	return nil
+}
+
+// fieldLengths returns the shortest S, O and H flags for the TSI and TOI.
+func (h *LCTHeader) fieldLengths() (s, o, hh int, err error) {
+	if h.TSI > MaxTSI {
+		err = errors.New("TSI out of range")
+		return
+	}
+	best := -1
+	for hf := 0; hf <= 1; hf++ {
+		for sf := 0; sf <= 1; sf++ {
+			for of := 0; of <= 3; of++ {
+				tsiBits := uint(32*sf + 16*hf)
+				toiBits := uint(32*of + 16*hf)
+				if !fits(h.TSI, tsiBits) || !fits(h.TOI, toiBits) {
+					continue
+				}
+				if size := tsiBits + toiBits; best < 0 || int(size) < best {
+					best, s, o, hh = int(size), sf, of, hf
+				}
+			}
+		}
+	}
+	return
+}
+
+func fits(v uint64, bits uint) bool {
+	return bits >= 64 || v>>bits == 0
+}
+
+// MarshalBinary returns the LCT header in its wire format.
+func (h *LCTHeader) MarshalBinary() (data []byte, err error) {
+	cci := h.CCI
+	if len(cci) == 0 {
+		cci = make([]byte, 4)
+	}
+	if len(cci)%4 != 0 || len(cci) > 16 {
+		err = errors.New("CCI must be 4, 8, 12 or 16 octets long")
+		return
+	}
+	s, o, hf, err := h.fieldLengths()
+	if err != nil {
+		return
+	}
+	tsiSize := 4*s + 2*hf
+	toiSize := 4*o + 2*hf
+	size := 4 + len(cci) + tsiSize + toiSize
+	for i := range h.Extensions {
+		ext := &h.Extensions[i]
+		switch {
+		case ext.Type >= 128 && len(ext.Content) != 3:
+			err = fmt.Errorf("header extension %d content must be 3 octets",
+				ext.Type)
+			return
+		case ext.Type < 128 && (ext.size()%4 != 0 || ext.size() > 255*4):
+			err = fmt.Errorf("header extension %d has invalid length",
+				ext.Type)
+			return
+		}
+		size += ext.size()
+	}
+	if size > 255*4 {
+		err = errors.New("LCT header too long")
+		return
+	}
+	data = make([]byte, size)
+	data[0] = LCTVersion<<4 | uint8(len(cci)/4-1)<<2 | h.PSI&3
+	data[1] = uint8(s)<<7 | uint8(o)<<5 | uint8(hf)<<4
+	if h.CloseSession {
+		data[1] |= 1 << 1
+	}
+	if h.CloseObject {
+		data[1] |= 1
+	}
+	data[2] = uint8(size / 4)
+	data[3] = h.Codepoint
+	b := data[4:]
+	b = b[copy(b, cci):]
+	putUint(b[:tsiSize], h.TSI)
+	b = b[tsiSize:]
+	putUint(b[:toiSize], h.TOI)
+	b = b[toiSize:]
+	for i := range h.Extensions {
+		ext := &h.Extensions[i]
+		b[0] = ext.Type
+		if ext.Type >= 128 {
+			copy(b[1:4], ext.Content)
+		} else {
+			b[1] = uint8(ext.size() / 4)
+			copy(b[2:], ext.Content)
+		}
+		b = b[ext.size():]
+	}
+	return
+}
+
+// ParseLCTHeader parses the LCT header at the beginning of b, and returns it
+// along with the rest of b.
+func ParseLCTHeader(b []byte) (h *LCTHeader, rest []byte, err error) {
+	if len(b) < 4 {
+		err = errors.New("LCT header too short")
+		return
+	}
+	if v := b[0] >> 4; v != LCTVersion {
+		err = fmt.Errorf("unsupported LCT version %d", v)
+		return
+	}
+	c := int(b[0]>>2&3) + 1
+	s := int(b[1] >> 7)
+	o := int(b[1] >> 5 & 3)
+	hf := int(b[1] >> 4 & 1)
+	size := int(b[2]) * 4
+	tsiSize := 4*s + 2*hf
+	toiSize := 4*o + 2*hf
+	fixedSize := 4 + 4*c + tsiSize + toiSize
+	if size < fixedSize || len(b) < size {
+		err = errors.New("LCT header too short")
+		return
+	}
+	h = &LCTHeader{
+		PSI:          b[0] & 3,
+		CloseSession: b[1]&(1<<1) != 0,
+		CloseObject:  b[1]&1 != 0,
+		Codepoint:    b[3],
+	}
+	p := b[4:size]
+	h.CCI = append([]byte(nil), p[:4*c]...)
+	p = p[4*c:]
+	h.TSI = getUint(p[:tsiSize])
+	p = p[tsiSize:]
+	if toiSize > 8 {
+		for _, x := range p[:toiSize-8] {
+			if x != 0 {
+				h = nil
+				err = errors.New("TOI longer than 64 bits")
+				return
+			}
+		}
+	}
+	h.TOI = getUint(p[:toiSize])
+	p = p[toiSize:]
+	for len(p) > 0 {
+		ext := HeaderExtension{Type: p[0]}
+		extSize := 4
+		if ext.Type < 128 {
+			if len(p) < 2 {
+				h, err = nil, errors.New("LCT header extension too short")
+				return
+			}
+			extSize = int(p[1]) * 4
+			if extSize < 4 {
+				h, err = nil, errors.New("LCT header extension too short")
+				return
+			}
+		}
+		if len(p) < extSize {
+			h, err = nil, errors.New("LCT header extension too long")
+			return
+		}
+		if ext.Type < 128 {
+			ext.Content = append([]byte(nil), p[2:extSize]...)
+		} else {
+			ext.Content = append([]byte(nil), p[1:4]...)
+		}
+		h.Extensions = append(h.Extensions, ext)
+		p = p[extSize:]
+	}
+	rest = b[size:]
+	return
+}
+
+// putUint writes v into b in network byte order, keeping its len(b) least
+// significant octets.
+func putUint(b []byte, v uint64) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = uint8(v)
+		v >>= 8
+	}
+}
+
+// getUint reads a network byte order integer from b, keeping its 8 least
+// significant octets.
+func getUint(b []byte) (v uint64) {
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+	return
+}
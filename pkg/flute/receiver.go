@@ -0,0 +1,434 @@
+package flute
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+)
+
+// DefaultMaxCompleted is the default number of received objects remembered
+// in order to drop late packets for them.
+const DefaultMaxCompleted = 1024
+
+// DefaultMaxPending is the default number of received files held until an
+// FDT Instance describes them.
+const DefaultMaxPending = 64
+
+// DefaultMaxActive is the default number of objects being received at once.
+const DefaultMaxActive = 64
+
+// DeliverFunc is called with each file received.
+type DeliverFunc func(f *File)
+
+// ReceiverConfig is the FLUTE receiver configuration.
+type ReceiverConfig struct {
+	// Factory creates the decoders, which must be RaptorQ ones.  If nil,
+	// the factory of defaults.RaptorQCodec is used.
+	Factory raptorq.DecoderFactory
+
+	// Deliver is called with each file received, once both the file and
+	// an FDT Instance describing it have been received.  It is called from
+	// a goroutine of its own.
+	Deliver DeliverFunc
+
+	// Timeout is how long received objects are remembered in order to drop
+	// late packets for them, how long received files are held until an FDT
+	// Instance describes them, and how long objects being received are kept
+	// without any packet for them.  Zero means forever.
+	Timeout time.Duration
+
+	// MaxCompleted is the maximum number of received objects remembered;
+	// the least recently received ones are forgotten first.  Zero means
+	// DefaultMaxCompleted.
+	MaxCompleted int
+
+	// MaxPending is the maximum number of received files held until an FDT
+	// Instance describes them; the least recently received ones are dropped
+	// first.  Zero means DefaultMaxPending.
+	MaxPending int
+
+	// MaxActive is the maximum number of objects being received at once;
+	// the decoders of those least recently heard from are closed first.
+	// Zero means DefaultMaxActive.
+	MaxActive int
+}
+
+// objectKey identifies an object in a session.  FDT Instances all have
+// TOI 0, so they are told apart by their FDT Instance ID.
+type objectKey struct {
+	toi        uint64
+	instanceID uint32
+}
+
+// completion records when an object was received.
+type completion struct {
+	key objectKey
+	at  time.Time
+}
+
+// activeObject is an object being received.
+type activeObject struct {
+	dec raptorq.Decoder
+	at  time.Time // when a packet for it was last received
+}
+
+// pendingFile is a received file not yet described by an FDT Instance.
+type pendingFile struct {
+	data []byte
+	at   time.Time
+}
+
+// Receiver receives files in one FLUTE session.
+type Receiver struct {
+	tsi       uint64
+	cfg       ReceiverConfig
+	mutex     sync.Mutex
+	decoders  map[objectKey]*activeObject
+	completed map[objectKey]time.Time
+	files     map[uint64]FDTFile
+	pending   map[uint64]pendingFile
+
+	// completedOrder and pendingOrder list received objects and pending
+	// files in order of reception, possibly including some forgotten or
+	// delivered since.
+	completedOrder []completion
+	pendingOrder   []completion
+	closed         bool
+}
+
+// NewReceiver returns a new receiver for the session identified by the given
+// TSI.
+func NewReceiver(tsi uint64, cfg ReceiverConfig) (r *Receiver, err error) {
+	if cfg.Factory == nil {
+		var codec registry.Codec
+		if codec, err = defaults.RaptorQCodec(); err != nil {
+			return
+		}
+		cfg.Factory = codec.DecoderFactory
+	}
+	if cfg.MaxCompleted <= 0 {
+		cfg.MaxCompleted = DefaultMaxCompleted
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = DefaultMaxPending
+	}
+	if cfg.MaxActive <= 0 {
+		cfg.MaxActive = DefaultMaxActive
+	}
+	r = &Receiver{
+		tsi:       tsi,
+		cfg:       cfg,
+		decoders:  make(map[objectKey]*activeObject),
+		completed: make(map[objectKey]time.Time),
+		files:     make(map[uint64]FDTFile),
+		pending:   make(map[uint64]pendingFile),
+	}
+	return
+}
+
+// HandlePacket handles one received ALC packet.
+//
+// HandlePacket returns an error for malformed packets, and for packets of
+// other sessions or FEC schemes.
+func (r *Receiver) HandlePacket(packet []byte) (err error) {
+	h, payload, err := ParseLCTHeader(packet)
+	if err != nil {
+		return
+	}
+	if h.TSI != r.tsi {
+		err = fmt.Errorf("packet of another session (TSI %d)", h.TSI)
+		return
+	}
+	if h.Codepoint != FECEncodingID {
+		err = fmt.Errorf("unsupported FEC Encoding ID %d", h.Codepoint)
+		return
+	}
+	key := objectKey{toi: h.TOI}
+	if h.TOI == FDTTOI {
+		ext := h.Extension(ExtFDT)
+		if ext == nil {
+			err = errors.New("FDT Instance packet without EXT_FDT")
+			return
+		}
+		if key.instanceID, err = ParseFDTExtension(ext); err != nil {
+			return
+		}
+	}
+	sbn, esi, err := raptorq.ParseFECPayloadID(payload)
+	if err != nil {
+		return
+	}
+	symbol := payload[raptorq.FECPayloadIDSize:]
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	r.evict(now)
+	// Decode under the lock, so that the decoder is not closed meanwhile.
+	dec, err := r.decoder(key, h, now)
+	if dec != nil && err == nil {
+		dec.Decode(sbn, esi, symbol)
+	}
+	return
+}
+
+// decoder returns the decoder for the given object, for a packet received at
+// the given time, creating one if needed, or nil if the object has already
+// been received.
+//
+// The caller must hold the mutex.
+func (r *Receiver) decoder(key objectKey, h *LCTHeader, now time.Time) (
+	dec raptorq.Decoder, err error,
+) {
+	if r.closed {
+		err = errors.New("receiver closed")
+		return
+	}
+	if _, ok := r.completed[key]; ok {
+		return
+	}
+	if a := r.decoders[key]; a != nil {
+		a.at = now
+		dec = a.dec
+		return
+	}
+	var commonOTI uint64
+	var schemeSpecificOTI uint32
+	if ext := h.Extension(ExtFTI); ext != nil {
+		commonOTI, schemeSpecificOTI, err = ParseFTI(ext)
+	} else if f, ok := r.files[key.toi]; ok && key.toi != FDTTOI {
+		commonOTI, schemeSpecificOTI, err = f.OTI()
+	} else {
+		err = errors.New("no FEC OTI for object")
+	}
+	if err != nil {
+		return
+	}
+	if dec, err = r.cfg.Factory.New(commonOTI, schemeSpecificOTI); err != nil {
+		return
+	}
+	if err = r.activate(key, dec, now); err != nil {
+		_ = dec.Close()
+		dec = nil
+	}
+	return
+}
+
+// activate starts receiving the given object with the given decoder, for a
+// packet received at the given time.  If MaxActive objects are already being
+// received, activate closes the decoder of the one least recently heard from.
+//
+// The caller must hold the mutex.
+func (r *Receiver) activate(
+	key objectKey, dec raptorq.Decoder, now time.Time,
+) (err error) {
+	ready := make(chan uint8, dec.NumSourceBlocks())
+	if err = dec.AddReadyBlockChan(ready); err != nil {
+		return
+	}
+	for len(r.decoders) >= r.cfg.MaxActive {
+		var oldest *activeObject
+		var oldestKey objectKey
+		for k, a := range r.decoders {
+			if oldest == nil || a.at.Before(oldest.at) {
+				oldest, oldestKey = a, k
+			}
+		}
+		delete(r.decoders, oldestKey)
+		_ = oldest.dec.Close()
+	}
+	r.decoders[key] = &activeObject{dec, now}
+	go r.awaitObject(key, dec, ready)
+	return
+}
+
+// awaitObject waits for all source blocks of the given object to become
+// ready, then completes the object.
+func (r *Receiver) awaitObject(
+	key objectKey, dec raptorq.Decoder, ready <-chan uint8,
+) {
+	numSourceBlocks := int(dec.NumSourceBlocks())
+	seen := make(map[uint8]bool, numSourceBlocks)
+	for sbn := range ready {
+		seen[sbn] = true
+		if len(seen) == numSourceBlocks {
+			r.complete(key, dec)
+			return
+		}
+	}
+	// The decoder has been closed.
+}
+
+func (r *Receiver) complete(key objectKey, dec raptorq.Decoder) {
+	r.mutex.Lock()
+	if a := r.decoders[key]; a == nil || a.dec != dec {
+		r.mutex.Unlock()
+		return
+	}
+	delete(r.decoders, key)
+	now := time.Now()
+	r.remember(key, now)
+	r.mutex.Unlock()
+	data := make([]byte, dec.TransferLength())
+	_, err := dec.SourceObject(data)
+	_ = dec.Close()
+	if err != nil {
+		return
+	}
+	var deliver []*File
+	r.mutex.Lock()
+	if key.toi == FDTTOI {
+		var fdt FDTInstance
+		if fdt.UnmarshalBinary(data) == nil {
+			for _, f := range fdt.Files {
+				if f.TOI == FDTTOI {
+					continue
+				}
+				r.files[f.TOI] = f
+				if p, ok := r.pending[f.TOI]; ok {
+					delete(r.pending, f.TOI)
+					deliver = append(deliver, newFile(&f, p.data))
+				}
+			}
+		}
+	} else if f, ok := r.files[key.toi]; ok {
+		deliver = append(deliver, newFile(&f, data))
+	} else {
+		// Hold on to the file until an FDT Instance describes it.
+		r.hold(key, data, now)
+	}
+	r.mutex.Unlock()
+	if r.cfg.Deliver != nil {
+		for _, f := range deliver {
+			r.cfg.Deliver(f)
+		}
+	}
+}
+
+// remember remembers the given object as received at the given time.
+//
+// The caller must hold the mutex.
+func (r *Receiver) remember(key objectKey, at time.Time) {
+	r.completed[key] = at
+	r.completedOrder = append(r.completedOrder, completion{key, at})
+	r.evict(at)
+}
+
+// hold holds the data of the given file, received at the given time, until
+// an FDT Instance describes it.
+//
+// The caller must hold the mutex.
+func (r *Receiver) hold(key objectKey, data []byte, at time.Time) {
+	r.pending[key.toi] = pendingFile{data, at}
+	r.pendingOrder = append(r.pendingOrder, completion{key, at})
+	r.evict(at)
+}
+
+// evict closes the decoders of objects being received past the timeout, then
+// forgets received objects and drops pending files past the timeout or beyond
+// their maximum numbers, oldest first.
+//
+// The caller must hold the mutex.
+func (r *Receiver) evict(now time.Time) {
+	expired := func(at time.Time) bool {
+		return r.cfg.Timeout > 0 && now.Sub(at) >= r.cfg.Timeout
+	}
+	// Closing a decoder also ends its awaitObject goroutine.
+	for key, a := range r.decoders {
+		if expired(a.at) {
+			delete(r.decoders, key)
+			_ = a.dec.Close()
+		}
+	}
+	for len(r.completedOrder) > 0 {
+		c := r.completedOrder[0]
+		live := r.isCompleted(c)
+		if live && len(r.completed) <= r.cfg.MaxCompleted && !expired(c.at) {
+			break
+		}
+		r.completedOrder = r.completedOrder[1:]
+		if live {
+			delete(r.completed, c.key)
+		}
+	}
+	for len(r.pendingOrder) > 0 {
+		c := r.pendingOrder[0]
+		live := r.isPending(c)
+		if live && len(r.pending) <= r.cfg.MaxPending && !expired(c.at) {
+			break
+		}
+		r.pendingOrder = r.pendingOrder[1:]
+		if live {
+			delete(r.pending, c.key.toi)
+		}
+	}
+	// Drop entries forgotten or delivered out of order, which the loops
+	// above only skip once they reach the front.
+	if len(r.completedOrder) > 2*r.cfg.MaxCompleted {
+		order := make([]completion, 0, len(r.completed))
+		for _, c := range r.completedOrder {
+			if r.isCompleted(c) {
+				order = append(order, c)
+			}
+		}
+		r.completedOrder = order
+	}
+	if len(r.pendingOrder) > 2*r.cfg.MaxPending {
+		order := make([]completion, 0, len(r.pending))
+		for _, c := range r.pendingOrder {
+			if r.isPending(c) {
+				order = append(order, c)
+			}
+		}
+		r.pendingOrder = order
+	}
+}
+
+// isCompleted tells whether the given entry of completedOrder is still
+// remembered.
+//
+// The caller must hold the mutex.
+func (r *Receiver) isCompleted(c completion) bool {
+	at, ok := r.completed[c.key]
+	return ok && at.Equal(c.at)
+}
+
+// isPending tells whether the given entry of pendingOrder is still pending.
+//
+// The caller must hold the mutex.
+func (r *Receiver) isPending(c completion) bool {
+	p, ok := r.pending[c.key.toi]
+	return ok && p.at.Equal(c.at)
+}
+
+func newFile(f *FDTFile, data []byte) *File {
+	return &File{
+		TOI:         f.TOI,
+		Name:        f.ContentLocation,
+		ContentType: f.ContentType,
+		Data:        data,
+	}
+}
+
+// Close closes the receiver along with the decoders of all objects being
+// received.
+func (r *Receiver) Close() (err error) {
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		err = errors.New("receiver already closed")
+		return
+	}
+	r.closed = true
+	decoders := r.decoders
+	r.decoders = nil
+	r.mutex.Unlock()
+	for _, a := range decoders {
+		_ = a.dec.Close()
+	}
+	return
+}
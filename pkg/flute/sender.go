@@ -0,0 +1,219 @@
+// Package flute delivers files over unidirectional transports, using FLUTE
+// (RFC 6726) on top of ALC (RFC 5775), with RaptorQ as the FEC scheme.
+//
+// Each ALC packet carries one encoding symbol, in the form:
+//
+//	LCT header || FEC Payload ID || encoding symbol
+//
+// where the LCT header carries the TSI and TOI, the FEC Encoding ID (6) in its
+// codepoint, and the EXT_FTI header extension with the object transmission
+// information of the object.  Files are described by FDT Instances, sent as
+// objects of TOI 0 with an EXT_FDT header extension.
+//
+// Other FEC schemes carry their FEC OTI in layouts of their own, so senders
+// and receivers reject codecs other than RaptorQ.
+package flute
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+	"github.com/harmony-one/go-raptorq/pkg/schedule"
+	"github.com/harmony-one/go-raptorq/pkg/sender"
+)
+
+// DefaultSymbolSize is the default encoding symbol size, in octets, chosen so
+// that ALC packets fit in a 1500-octet Ethernet MTU over UDP.
+const DefaultSymbolSize = 1400
+
+// DefaultMaxSubBlockSize is the default maximum sub-block size, in octets.
+const DefaultMaxSubBlockSize = 8 << 20
+
+// DefaultFDTExpiry is how long FDT Instances are valid by default.
+const DefaultFDTExpiry = time.Hour
+
+// File is a file delivered in a FLUTE session.
+type File struct {
+	// TOI is the Transport Object Identifier of the file.  When sending,
+	// zero means the sender assigns one.
+	TOI uint64
+
+	// Name is the Content-Location of the file, a URI.
+	Name string
+
+	// ContentType is the MIME media type of the file, if known.
+	ContentType string
+
+	Data []byte
+}
+
+// SenderConfig is the FLUTE sender configuration.
+type SenderConfig struct {
+	// Factory creates the encoders, which must be RaptorQ ones.  If nil, the
+	// factory of defaults.RaptorQCodec is used.
+	Factory raptorq.EncoderFactory
+
+	// SymbolSize is the encoding symbol size, in octets.  If zero,
+	// DefaultSymbolSize is used.
+	SymbolSize uint16
+
+	// MaxSubBlockSize is the maximum sub-block size, in octets.  If zero,
+	// DefaultMaxSubBlockSize is used.
+	MaxSubBlockSize uint32
+
+	// Redundancy is the ratio of repair symbols sent for each source block,
+	// relative to its number of source symbols.
+	Redundancy float64
+
+	// Rate is the sending rate, in packets per second.  Zero means no
+	// pacing.
+	Rate float64
+
+	// FDTExpiry is how long FDT Instances are valid.  If zero,
+	// DefaultFDTExpiry is used.
+	FDTExpiry time.Duration
+}
+
+// Sender sends files in one FLUTE session.
+type Sender struct {
+	tsi            uint64
+	send           sender.SendFunc
+	cfg            SenderConfig
+	nextTOI        uint64
+	nextInstanceID uint32
+}
+
+// NewSender returns a new sender for the session identified by the given TSI,
+// which sends ALC packets using the given function.
+func NewSender(tsi uint64, send sender.SendFunc, cfg SenderConfig) (
+	s *Sender, err error,
+) {
+	if tsi > MaxTSI {
+		err = errors.New("TSI out of range")
+		return
+	}
+	if cfg.Factory == nil {
+		var codec registry.Codec
+		if codec, err = defaults.RaptorQCodec(); err != nil {
+			return
+		}
+		cfg.Factory = codec.EncoderFactory
+	}
+	if cfg.SymbolSize == 0 {
+		cfg.SymbolSize = DefaultSymbolSize
+	}
+	if cfg.MaxSubBlockSize == 0 {
+		cfg.MaxSubBlockSize = DefaultMaxSubBlockSize
+	}
+	if cfg.FDTExpiry == 0 {
+		cfg.FDTExpiry = DefaultFDTExpiry
+	}
+	s = &Sender{tsi: tsi, send: send, cfg: cfg, nextTOI: 1}
+	return
+}
+
+// Send sends the given files: first an FDT Instance describing them, then
+// each file in turn.  Files with a zero TOI are assigned one, which Send
+// stores back into files.
+//
+// Send returns when all encoding symbols of the schedule implied by the
+// redundancy have been sent for every object, or the context is done.
+func (s *Sender) Send(ctx context.Context, files []File) (err error) {
+	encs := make([]raptorq.Encoder, len(files))
+	defer func() {
+		for _, enc := range encs {
+			if enc != nil {
+				_ = enc.Close()
+			}
+		}
+	}()
+	fdt := &FDTInstance{
+		Expires: NTPTime(time.Now().Add(s.cfg.FDTExpiry)),
+		Files:   make([]FDTFile, len(files)),
+	}
+	for i := range files {
+		f := &files[i]
+		if len(f.Data) == 0 {
+			err = errors.New("empty files are not supported")
+			return
+		}
+		if f.TOI == FDTTOI {
+			f.TOI = s.nextTOI
+			s.nextTOI++
+		}
+		if encs[i], err = s.encoder(f.Data); err != nil {
+			return
+		}
+		fdt.Files[i] = FDTFile{
+			TOI:             f.TOI,
+			ContentLocation: f.Name,
+			ContentLength:   uint64(len(f.Data)),
+			ContentType:     f.ContentType,
+		}
+		fdt.Files[i].SetOTI(encs[i].CommonOTI(), encs[i].SchemeSpecificOTI())
+	}
+	fdtData, err := fdt.MarshalBinary()
+	if err != nil {
+		return
+	}
+	fdtEnc, err := s.encoder(fdtData)
+	if err != nil {
+		return
+	}
+	defer fdtEnc.Close()
+	instanceID := s.nextInstanceID
+	s.nextInstanceID = (s.nextInstanceID + 1) & MaxFDTInstanceID
+	fdtExt := FDTExtension(instanceID)
+	if err = s.sendObject(ctx, FDTTOI, fdtEnc, fdtExt); err != nil {
+		return
+	}
+	for i := range files {
+		if err = s.sendObject(ctx, files[i].TOI, encs[i]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// encoder returns an encoder of the given data, or an error if the factory
+// is not of RaptorQ.
+func (s *Sender) encoder(data []byte) (enc raptorq.Encoder, err error) {
+	enc, err = s.cfg.Factory.New(data, s.cfg.SymbolSize, s.cfg.SymbolSize,
+		s.cfg.MaxSubBlockSize, 1)
+	if err != nil {
+		return
+	}
+	if err = checkOTI(enc.CommonOTI(), enc.SchemeSpecificOTI()); err != nil {
+		_ = enc.Close()
+		enc = nil
+	}
+	return
+}
+
+// sendObject sends the encoding symbols of the given object.
+func (s *Sender) sendObject(
+	ctx context.Context, toi uint64, enc raptorq.Encoder,
+	exts ...HeaderExtension,
+) (err error) {
+	h := &LCTHeader{
+		TSI:       s.tsi,
+		TOI:       toi,
+		Codepoint: FECEncodingID,
+		Extensions: append(exts,
+			FTIExtension(enc.CommonOTI(), enc.SchemeSpecificOTI())),
+	}
+	header, err := h.MarshalBinary()
+	if err != nil {
+		return
+	}
+	sched := schedule.New(enc, schedule.Config{Redundancy: s.cfg.Redundancy})
+	return sender.New(enc, sched, s.send, sender.Config{
+		Rate:   s.cfg.Rate,
+		Burst:  16,
+		Header: header,
+	}).Run(ctx, nil)
+}
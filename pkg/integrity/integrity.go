@@ -0,0 +1,304 @@
+// Package integrity detects source objects decoded to garbage because of
+// corrupted encoding symbols, and isolates the corrupted symbols.
+//
+// A decoder cannot tell a corrupted encoding symbol from a genuine one; it
+// solves for the source block using whichever encoding symbols it has, and
+// the corruption spreads over the recovered source block.  Given the expected
+// hash of the source object, Recover detects this, then looks for the
+// corrupted symbols by re-encoding: each candidate source block is checked
+// against every encoding symbol received for it, and the received symbols that
+// do not match are suspect.  A candidate derived from a corrupted symbol is
+// contradicted by the genuine symbols left out of its derivation, so Recover
+// decodes again from different subsets, suspects first, until the source
+// object matches its hash.  The symbols inconsistent with the genuine source
+// blocks are the corrupted ones.
+//
+// Isolation needs encoding symbols beyond the minimum: a corrupted symbol is
+// only exposed by genuine symbols of the same source block that contradict it.
+package integrity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// DefaultMaxAttempts is the default maximum number of times a source block is
+// decoded again from a different subset of its encoding symbols.
+const DefaultMaxAttempts = 32
+
+// Config is the configuration of Recover.
+type Config struct {
+	// Factory creates decoders for the source object.
+	Factory raptorq.DecoderFactory
+
+	// CommonOTI and SchemeSpecificOTI are the object transmission
+	// information of the source object.
+	CommonOTI         uint64
+	SchemeSpecificOTI uint32
+
+	// Hash is the expected SHA-256 hash of the source object.
+	Hash [sha256.Size]byte
+
+	// MaxAttempts is the maximum number of times each source block is
+	// decoded again from a different subset of its encoding symbols.  Zero
+	// means DefaultMaxAttempts.
+	MaxAttempts int
+}
+
+// SourceBlockNotRecoverable signals the given source block could not be
+// recovered from the encoding symbols received for it, or from any subset of
+// them tried.
+type SourceBlockNotRecoverable uint8
+
+func (e SourceBlockNotRecoverable) Error() string {
+	return fmt.Sprintf("source block %d not recoverable", uint8(e))
+}
+
+// block is the state of isolation for one source block.
+type block struct {
+	sbn     uint8
+	k       uint16
+	symbols []raptorq.Symbol
+
+	// data is the best candidate source block so far, and bad the indices
+	// of the symbols inconsistent with it.
+	data []byte
+	bad  []int
+}
+
+type recovery struct {
+	cfg    Config
+	blocks []*block
+	rng    *rand.Rand
+}
+
+// Recover decodes the source object from the given received encoding
+// symbols, and verifies it against the expected hash.
+//
+// If the source object does not match the hash, Recover isolates the
+// corrupted symbols as described in the package documentation, then decodes
+// the source object again without them.  It returns the source object along
+// with the corrupted symbols, if any.
+//
+// Recover returns a SourceBlockNotRecoverable error if a source block cannot
+// be recovered from the symbols, and an error if no source object matching
+// the hash could be found.
+func Recover(symbols []raptorq.Symbol, cfg Config) (
+	object []byte, corrupted []raptorq.Symbol, err error,
+) {
+	if cfg.Factory == nil {
+		err = errors.New("decoder factory required")
+		return
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	r := &recovery{cfg: cfg, rng: rand.New(rand.NewSource(1))}
+	if object, err = r.decodeAll(symbols); err != nil {
+		object = nil
+		return
+	}
+	if r.verify(object) {
+		return
+	}
+	object = nil
+	if err = r.isolate(); err != nil {
+		return
+	}
+	var genuine []raptorq.Symbol
+	for _, b := range r.blocks {
+		bad := make(map[int]bool, len(b.bad))
+		for _, i := range b.bad {
+			bad[i] = true
+			corrupted = append(corrupted, b.symbols[i])
+		}
+		for i, symbol := range b.symbols {
+			if !bad[i] {
+				genuine = append(genuine, symbol)
+			}
+		}
+	}
+	// Retry without the corrupted symbols.
+	if object, err = r.decodeAll(genuine); err == nil && !r.verify(object) {
+		err = errors.New("source object hash mismatch")
+	}
+	if err != nil {
+		object, corrupted = nil, nil
+	}
+	return
+}
+
+func (r *recovery) verify(object []byte) bool {
+	return sha256.Sum256(object) == r.cfg.Hash
+}
+
+// decodeAll decodes the source object from the given symbols, and sets up
+// the state of isolation for each source block from the result.
+func (r *recovery) decodeAll(symbols []raptorq.Symbol) (
+	object []byte, err error,
+) {
+	dec, err := r.cfg.Factory.New(r.cfg.CommonOTI, r.cfg.SchemeSpecificOTI)
+	if err != nil {
+		return
+	}
+	defer dec.Close()
+	r.blocks = make([]*block, dec.NumSourceBlocks())
+	for sbn := range r.blocks {
+		r.blocks[sbn] = &block{
+			sbn: uint8(sbn),
+			k:   dec.NumSourceSymbols(uint8(sbn)),
+		}
+	}
+	for _, symbol := range symbols {
+		if int(symbol.SBN) < len(r.blocks) {
+			b := r.blocks[symbol.SBN]
+			b.symbols = append(b.symbols, symbol)
+		}
+	}
+	dec.DecodeBatch(symbols)
+	object = make([]byte, 0, dec.TransferLength())
+	for _, b := range r.blocks {
+		var ok bool
+		if b.data, b.bad, ok, err = r.check(dec, b); err != nil {
+			return
+		}
+		if !ok {
+			err = SourceBlockNotRecoverable(b.sbn)
+			return
+		}
+		object = append(object, b.data...)
+	}
+	return
+}
+
+// check retrieves the given source block from the given decoder, which has
+// been fed with some of its symbols, and returns it along with the indices of
+// the symbols inconsistent with it, or ok = false if the decoder could not
+// recover the source block.
+func (r *recovery) check(dec raptorq.Decoder, b *block) (
+	data []byte, bad []int, ok bool, err error,
+) {
+	if !dec.IsSourceBlockReady(b.sbn) {
+		// Have the decoder try with what it has.
+		if _, err = dec.EndOfInput(b.sbn, false); err != nil {
+			return
+		}
+		if !dec.IsSourceBlockReady(b.sbn) {
+			return
+		}
+	}
+	data = make([]byte, dec.SourceBlockSize(b.sbn))
+	if _, err = dec.SourceBlock(b.sbn, data); err != nil {
+		return
+	}
+	enc, err := dec.Reencoder()
+	if err != nil {
+		return
+	}
+	defer enc.Close()
+	buf := make([]byte, dec.SymbolSize())
+	for i, symbol := range b.symbols {
+		if _, err = enc.Encode(b.sbn, symbol.ESI, buf); err != nil {
+			return
+		}
+		if !bytes.Equal(buf, symbol.Data) {
+			bad = append(bad, i)
+		}
+	}
+	ok = true
+	return
+}
+
+// isolate decodes source blocks inconsistent with their symbols again from
+// different subsets of their symbols, until the source object matches its
+// hash.
+//
+// A candidate source block replaces the best one so far if it is consistent
+// with more symbols, or if it makes the source object match its hash; the
+// latter breaks ties, e.g. between a corrupted symbol and its only genuine
+// counterpart.
+func (r *recovery) isolate() (err error) {
+	for _, b := range r.blocks {
+		for i := 0; i < r.cfg.MaxAttempts && len(b.bad) > 0; i++ {
+			var data []byte
+			var bad []int
+			var ok bool
+			if data, bad, ok, err = r.retry(b); err != nil {
+				return
+			}
+			if !ok {
+				continue
+			}
+			prevData, prevBad := b.data, b.bad
+			b.data, b.bad = data, bad
+			if r.verify(r.assemble()) {
+				return
+			}
+			if len(bad) >= len(prevBad) {
+				b.data, b.bad = prevData, prevBad
+			}
+		}
+	}
+	if !r.verify(r.assemble()) {
+		err = errors.New("corrupted symbols could not be isolated")
+	}
+	return
+}
+
+// retry decodes the given source block again from as few symbols as
+// possible, taking the symbols inconsistent with the best candidate so far
+// first, followed by the others in random order, and returns the new
+// candidate source block along with the indices of the symbols inconsistent
+// with it, or ok = false if it could not be recovered.
+func (r *recovery) retry(b *block) (
+	data []byte, bad []int, ok bool, err error,
+) {
+	order := make([]int, 0, len(b.symbols))
+	suspect := make(map[int]bool, len(b.bad))
+	for _, i := range b.bad {
+		suspect[i] = true
+		order = append(order, i)
+	}
+	for _, i := range r.rng.Perm(len(b.symbols)) {
+		if !suspect[i] {
+			order = append(order, i)
+		}
+	}
+	// Codes other than MDS codes may need a few more symbols than there are
+	// source symbols.
+	for n := int(b.k); n <= len(order) && !ok && err == nil; n++ {
+		data, bad, ok, err = r.decodeSubset(b, order[:n])
+	}
+	return
+}
+
+// decodeSubset decodes the given source block from the given subset of its
+// symbols.
+func (r *recovery) decodeSubset(b *block, subset []int) (
+	data []byte, bad []int, ok bool, err error,
+) {
+	dec, err := r.cfg.Factory.New(r.cfg.CommonOTI, r.cfg.SchemeSpecificOTI)
+	if err != nil {
+		return
+	}
+	defer dec.Close()
+	for _, i := range subset {
+		symbol := b.symbols[i]
+		dec.Decode(symbol.SBN, symbol.ESI, symbol.Data)
+	}
+	return r.check(dec, b)
+}
+
+// assemble returns the source object made of the best candidate source
+// blocks so far.
+func (r *recovery) assemble() (object []byte) {
+	for _, b := range r.blocks {
+		object = append(object, b.data...)
+	}
+	return
+}
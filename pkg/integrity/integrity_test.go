@@ -0,0 +1,119 @@
+package integrity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+type codec struct {
+	name             string
+	enc              raptorq.EncoderFactory
+	dec              raptorq.DecoderFactory
+	size, symbolSize int
+	maxSubBlockSize  uint32
+}
+
+var codecs = testCodecs()
+
+// testCodecs returns the codecs the tests run with: RaptorQ, if the build has
+// it, and Reed-Solomon.
+func testCodecs() (codecs []codec) {
+	if rq, err := defaults.RaptorQCodec(); err == nil {
+		codecs = append(codecs, codec{"raptorq", rq.EncoderFactory,
+			rq.DecoderFactory, 20000, 200, 10000})
+	}
+	return append(codecs, codec{"rs", defaults.ReedSolomonEncoderFactory(),
+		defaults.ReedSolomonDecoderFactory(), 2000, 100, 2000})
+}
+
+// receive returns the first K + extra encoding symbols of each source block
+// of a random source object, along with the configuration to recover it.
+func receive(t *testing.T, c codec, extra uint32) (
+	object []byte, symbols []raptorq.Symbol, cfg Config,
+) {
+	rng := rand.New(rand.NewSource(1))
+	object = make([]byte, c.size)
+	rng.Read(object)
+	enc, err := c.enc.New(object, uint16(c.symbolSize), uint16(c.symbolSize),
+		c.maxSubBlockSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	for sbn := uint8(0); sbn < enc.NumSourceBlocks(); sbn++ {
+		n := uint32(enc.NumSourceSymbols(sbn)) + extra
+		for esi := uint32(0); esi < n; esi++ {
+			buf := make([]byte, enc.SymbolSize())
+			if _, err = enc.Encode(sbn, esi, buf); err != nil {
+				t.Fatal(err)
+			}
+			symbols = append(symbols, raptorq.Symbol{
+				SBN: sbn, ESI: esi, Data: buf,
+			})
+		}
+	}
+	cfg = Config{
+		Factory:           c.dec,
+		CommonOTI:         enc.CommonOTI(),
+		SchemeSpecificOTI: enc.SchemeSpecificOTI(),
+		Hash:              sha256.Sum256(object),
+	}
+	return
+}
+
+func TestRecoverIntact(t *testing.T) {
+	for _, c := range codecs {
+		object, symbols, cfg := receive(t, c, 2)
+		got, corrupted, err := Recover(symbols, cfg)
+		if err != nil || !bytes.Equal(got, object) || len(corrupted) != 0 {
+			t.Errorf("%s: Recover() = %d octets, %v, %v",
+				c.name, len(got), corrupted, err)
+		}
+	}
+}
+
+func TestRecoverCorrupted(t *testing.T) {
+	for _, c := range codecs {
+		object, symbols, cfg := receive(t, c, 6)
+		// Corrupt a source symbol and a repair symbol.
+		want := map[[2]uint32]bool{}
+		for _, i := range []int{3, len(symbols) - 2} {
+			symbols[i].Data[7] ^= 0x55
+			want[[2]uint32{uint32(symbols[i].SBN), symbols[i].ESI}] = true
+		}
+		got, corrupted, err := Recover(symbols, cfg)
+		if err != nil {
+			t.Errorf("%s: Recover() error: %v", c.name, err)
+			continue
+		}
+		if !bytes.Equal(got, object) {
+			t.Errorf("%s: recovered object mismatch", c.name)
+		}
+		if len(corrupted) != len(want) {
+			t.Errorf("%s: %d corrupted symbols, want %d",
+				c.name, len(corrupted), len(want))
+		}
+		for _, s := range corrupted {
+			if !want[[2]uint32{uint32(s.SBN), s.ESI}] {
+				t.Errorf("%s: genuine symbol %d/%d reported corrupted",
+					c.name, s.SBN, s.ESI)
+			}
+		}
+	}
+}
+
+func TestRecoverNotRecoverable(t *testing.T) {
+	for _, c := range codecs {
+		_, symbols, cfg := receive(t, c, 0)
+		_, _, err := Recover(symbols[1:], cfg)
+		if _, ok := err.(SourceBlockNotRecoverable); !ok {
+			t.Errorf("%s: Recover() error = %v, want not recoverable",
+				c.name, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+)
+
+// CommitmentSize is the size of a signed commitment, in octets.
+const CommitmentSize = receiver.HeaderSize + HashSize + ed25519.SignatureSize
+
+// signingContext prefixes the signed message, so that commitment signatures
+// cannot be confused with other messages signed under the same key.
+const signingContext = "go-raptorq merkle commitment v1\x00"
+
+// Commitment is a signed commitment to the encoding symbols of a source
+// object.  Its wire format is:
+//
+//	Object header  (20 octets; see receiver.Header)
+//	Root           (32 octets)
+//	Signature      (64 octets)
+//
+// The Ed25519 signature covers the signing context
+// "go-raptorq merkle commitment v1" followed by a zero octet, the object
+// header and the root, so it also binds the object transmission information
+// advertised in the packets of the source object.
+type Commitment struct {
+	Header    receiver.Header
+	Root      Hash
+	Signature [ed25519.SignatureSize]byte
+}
+
+func (c *Commitment) message() []byte {
+	b := make([]byte, 0, len(signingContext)+receiver.HeaderSize+HashSize)
+	b = append(b, signingContext...)
+	b = append(b, c.Header.Bytes()...)
+	return append(b, c.Root[:]...)
+}
+
+// Sign signs the commitment with the given private key.
+func (c *Commitment) Sign(key ed25519.PrivateKey) {
+	copy(c.Signature[:], ed25519.Sign(key, c.message()))
+}
+
+// Verify returns whether the commitment is signed with the private key
+// matching the given public key.
+func (c *Commitment) Verify(key ed25519.PublicKey) bool {
+	return ed25519.Verify(key, c.message(), c.Signature[:])
+}
+
+// Bytes returns the commitment in its wire format.
+func (c *Commitment) Bytes() []byte {
+	b := make([]byte, 0, CommitmentSize)
+	b = append(b, c.Header.Bytes()...)
+	b = append(b, c.Root[:]...)
+	return append(b, c.Signature[:]...)
+}
+
+// ParseCommitment parses the given commitment.  It does not verify the
+// signature.
+func ParseCommitment(b []byte) (c Commitment, err error) {
+	if len(b) != CommitmentSize {
+		err = errors.New("commitment size mismatch")
+		return
+	}
+	if c.Header, err = receiver.ParseHeader(b); err != nil {
+		return
+	}
+	b = b[receiver.HeaderSize:]
+	b = b[copy(c.Root[:], b):]
+	copy(c.Signature[:], b)
+	return
+}
@@ -0,0 +1,140 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"math"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+	"github.com/harmony-one/go-raptorq/pkg/sender"
+)
+
+// Committer commits to the encoding symbols of a source object on the sending
+// side, and attaches inclusion proofs to packets.
+type Committer struct {
+	commitment Commitment
+	blocks     []tree
+	counts     []uint32
+	object     tree
+}
+
+// NewCommitter encodes the committed encoding symbols of the given encoder,
+// and returns a committer for them whose commitment is signed with the given
+// private key.
+//
+// For each source block of K source symbols, the committed encoding symbols
+// are those with ESIs 0 through ceil(K × (1 + redundancy)) - 1, capped at the
+// maximum number of encoding symbols; these are the encoding symbols sent by a
+// schedule.Schedule with the same redundancy and without an ESI allocation.
+//
+// NewCommitter returns an error unless the key is ed25519.PrivateKeySize
+// octets long.
+func NewCommitter(
+	enc raptorq.Encoder, id receiver.ObjectID, redundancy float64,
+	key ed25519.PrivateKey,
+) (c *Committer, err error) {
+	if redundancy < 0 {
+		err = errors.New("redundancy must not be negative")
+		return
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		err = errors.New("Ed25519 private key size must be 64 octets")
+		return
+	}
+	numSourceBlocks := int(enc.NumSourceBlocks())
+	c = &Committer{
+		blocks: make([]tree, numSourceBlocks),
+		counts: make([]uint32, numSourceBlocks),
+	}
+	symbol := make([]byte, enc.SymbolSize())
+	roots := make([]Hash, numSourceBlocks)
+	for sbn := range c.blocks {
+		k := float64(enc.NumSourceSymbols(uint8(sbn)))
+		count := uint32(math.Ceil(k * (1 + redundancy)))
+		if maxSymbols := enc.MaxSymbols(uint8(sbn)); count > maxSymbols {
+			count = maxSymbols
+		}
+		leaves := make([]Hash, count)
+		for esi := range leaves {
+			var written uint
+			written, err = enc.Encode(uint8(sbn), uint32(esi), symbol)
+			if err != nil {
+				c = nil
+				return
+			}
+			if written != uint(len(symbol)) {
+				c = nil
+				err = errors.New("encoder returned a short symbol")
+				return
+			}
+			leaves[esi] = Leaf(uint8(sbn), uint32(esi), symbol)
+		}
+		c.blocks[sbn] = newTree(leaves)
+		c.counts[sbn] = count
+		roots[sbn] = c.blocks[sbn].root()
+	}
+	c.object = newTree(roots)
+	c.commitment = Commitment{
+		Header: receiver.HeaderFor(id, enc),
+		Root:   c.object.root(),
+	}
+	c.commitment.Sign(key)
+	return
+}
+
+// Commitment returns the signed commitment, to be sent to receivers ahead of
+// the packets of the source object.
+func (c *Committer) Commitment() Commitment {
+	return c.commitment
+}
+
+// NumCommitted returns the number of encoding symbols committed to for the
+// given source block.
+func (c *Committer) NumCommitted(sbn uint8) uint32 {
+	if int(sbn) >= len(c.counts) {
+		return 0
+	}
+	return c.counts[sbn]
+}
+
+// Proof returns the inclusion proof of the given encoding symbol.
+func (c *Committer) Proof(sbn uint8, esi uint32) (p Proof, err error) {
+	if esi >= c.NumCommitted(sbn) {
+		err = errors.New("encoding symbol not committed")
+		return
+	}
+	p.BlockPath = c.blocks[sbn].path(esi)
+	p.ObjectPath = c.object.path(uint32(sbn))
+	return
+}
+
+// SendFunc returns a sender.SendFunc that appends the inclusion proof to each
+// packet of the form:
+//
+//	Header || FEC Payload ID || encoding symbol
+//
+// before sending it using the given function.  Sending an encoding symbol
+// that has not been committed to fails.
+//
+// Each packet with its proof is a fresh copy, so send may retain it, and the
+// returned function may be called concurrently if send allows it.
+func (c *Committer) SendFunc(send sender.SendFunc) sender.SendFunc {
+	return func(packet []byte) error {
+		if len(packet) < receiver.HeaderSize {
+			return errors.New("object header too short")
+		}
+		sbn, esi, err := raptorq.ParseFECPayloadID(
+			packet[receiver.HeaderSize:])
+		if err != nil {
+			return err
+		}
+		p, err := c.Proof(sbn, esi)
+		if err != nil {
+			return err
+		}
+		buf := make([]byte, len(packet), len(packet)+p.Size())
+		copy(buf, packet)
+		return send(p.Append(buf))
+	}
+}
@@ -0,0 +1,130 @@
+package merkle
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+)
+
+// HandleFunc handles one packet, e.g. receiver.Receiver.HandlePacket.
+type HandleFunc func(packet []byte) error
+
+// NoCommitment signals a packet arrived for a source object whose commitment
+// has not been received.
+type NoCommitment receiver.ObjectID
+
+func (e NoCommitment) Error() string {
+	return fmt.Sprintf("object %d: no commitment", uint64(e))
+}
+
+// Filter is a receiving front-end that verifies the inclusion proof of each
+// packet against the signed commitment of its source object, and passes those
+// verified, without their proof, on to the next handler.
+type Filter struct {
+	key         ed25519.PublicKey
+	next        HandleFunc
+	mutex       sync.Mutex
+	commitments map[receiver.ObjectID]*Commitment
+	dropped     uint64
+}
+
+// NewFilter returns a new filter trusting commitments signed with the private
+// key matching the given public key, and passing verified packets on to next.
+//
+// NewFilter returns an error unless the key is ed25519.PublicKeySize octets
+// long.
+func NewFilter(key ed25519.PublicKey, next HandleFunc) (f *Filter, err error) {
+	if len(key) != ed25519.PublicKeySize {
+		err = errors.New("Ed25519 public key size must be 32 octets")
+		return
+	}
+	f = &Filter{
+		key:         key,
+		next:        next,
+		commitments: make(map[receiver.ObjectID]*Commitment),
+	}
+	return
+}
+
+// HandleCommitment handles a commitment in its wire format, as sent by the
+// sender.
+//
+// HandleCommitment returns an error if the signature is invalid, or if a
+// different commitment has been received for the same source object.
+func (f *Filter) HandleCommitment(b []byte) (err error) {
+	c, err := ParseCommitment(b)
+	if err != nil {
+		return
+	}
+	if !c.Verify(f.key) {
+		err = errors.New("commitment signature invalid")
+		return
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if prev, ok := f.commitments[c.Header.ObjectID]; ok {
+		if *prev != c {
+			err = errors.New("conflicting commitment")
+		}
+		return
+	}
+	f.commitments[c.Header.ObjectID] = &c
+	return
+}
+
+// Forget forgets the commitment for the given source object, e.g. once it
+// has been recovered.  Packets for it are dropped afterwards.
+func (f *Filter) Forget(id receiver.ObjectID) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.commitments, id)
+}
+
+// HandlePacket verifies the given packet, and passes it on if verified.
+// Otherwise it drops the packet and returns an error.
+func (f *Filter) HandlePacket(packet []byte) (err error) {
+	payload, err := f.verify(packet)
+	if err != nil {
+		atomic.AddUint64(&f.dropped, 1)
+		return
+	}
+	return f.next(payload)
+}
+
+func (f *Filter) verify(packet []byte) (payload []byte, err error) {
+	p, payload, err := ParseProof(packet)
+	if err != nil {
+		return
+	}
+	h, err := receiver.ParseHeader(payload)
+	if err != nil {
+		return
+	}
+	rest := payload[receiver.HeaderSize:]
+	sbn, esi, err := raptorq.ParseFECPayloadID(rest)
+	if err != nil {
+		return
+	}
+	f.mutex.Lock()
+	c, ok := f.commitments[h.ObjectID]
+	f.mutex.Unlock()
+	switch {
+	case !ok:
+		err = NoCommitment(h.ObjectID)
+	case c.Header != h:
+		err = receiver.OTIMismatch(h.ObjectID)
+	case !p.Verify(&c.Root, sbn, esi, rest[raptorq.FECPayloadIDSize:]):
+		err = errors.New("inclusion proof invalid")
+	}
+	return
+}
+
+// Dropped returns the number of packets dropped so far.
+func (f *Filter) Dropped() uint64 {
+	return atomic.LoadUint64(&f.dropped)
+}
@@ -0,0 +1,240 @@
+package merkle
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+	"github.com/harmony-one/go-raptorq/pkg/schedule"
+	"github.com/harmony-one/go-raptorq/pkg/sender"
+)
+
+func TestProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 9} {
+		symbols := make([][]byte, n)
+		leaves := make([]Hash, n)
+		for esi := range symbols {
+			symbols[esi] = []byte{byte(esi), byte(n)}
+			leaves[esi] = Leaf(3, uint32(esi), symbols[esi])
+		}
+		block := newTree(leaves)
+		object := newTree([]Hash{{}, {}, {}, block.root()})
+		root := object.root()
+		for esi := range symbols {
+			p := Proof{
+				BlockPath:  block.path(uint32(esi)),
+				ObjectPath: object.path(3),
+			}
+			b := p.Append([]byte("packet"))
+			if len(b) != len("packet")+p.Size() {
+				t.Errorf("n=%d: proof size %d", n, len(b)-len("packet"))
+			}
+			q, rest, err := ParseProof(b)
+			if err != nil || string(rest) != "packet" {
+				t.Fatalf("n=%d: ParseProof() = %q, %v", n, rest, err)
+			}
+			if !q.Verify(&root, 3, uint32(esi), symbols[esi]) {
+				t.Errorf("n=%d esi=%d: valid proof rejected", n, esi)
+			}
+			if q.Verify(&root, 2, uint32(esi), symbols[esi]) ||
+				q.Verify(&root, 3, uint32(esi+1), symbols[esi]) ||
+				q.Verify(&root, 3, uint32(esi), []byte{0xff, 0xff}) {
+				t.Errorf("n=%d esi=%d: invalid proof accepted", n, esi)
+			}
+		}
+	}
+}
+
+func TestCommitment(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Commitment{
+		Header: receiver.Header{ObjectID: 7, CommonOTI: 1, SchemeSpecificOTI: 2},
+		Root:   Hash{1, 2, 3},
+	}
+	c.Sign(priv)
+	b := c.Bytes()
+	d, err := ParseCommitment(b)
+	if err != nil || d != c || !d.Verify(pub) {
+		t.Fatalf("ParseCommitment() = %+v, %v", d, err)
+	}
+	for i := range b {
+		tampered := append([]byte(nil), b...)
+		tampered[i] ^= 1
+		if d, err := ParseCommitment(tampered); err == nil && d.Verify(pub) {
+			t.Errorf("tampered octet %d accepted", i)
+		}
+	}
+}
+
+func TestKeySize(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []ed25519.PublicKey{nil, pub[:len(pub)-1]} {
+		if _, err := NewFilter(key, nil); err == nil {
+			t.Errorf("%d-octet public key accepted", len(key))
+		}
+	}
+	enc, err := defaults.ReedSolomonEncoderFactory().New(make([]byte, 100),
+		10, 10, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	if _, err := NewCommitter(enc, 1, 0, priv[:ed25519.SeedSize]); err == nil {
+		t.Error("32-octet private key accepted")
+	}
+}
+
+// TestForgedSymbols sends a source object through a Byzantine relay that
+// corrupts some packets and injects others.
+func TestForgedSymbols(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	pub, priv, err := ed25519.GenerateKey(rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := make([]byte, 50000)
+	rng.Read(object)
+	enc, err := defaults.ReedSolomonEncoderFactory().New(object,
+		500, 500, 20000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	const redundancy = 0.5
+	committer, err := NewCommitter(enc, 42, redundancy, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delivered := make(chan []byte, 1)
+	r, err := receiver.New(receiver.Config{
+		Factory: defaults.ReedSolomonDecoderFactory(),
+		Deliver: func(id receiver.ObjectID, object []byte) {
+			delivered <- object
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	filter, err := NewFilter(pub, r.HandlePacket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment := committer.Commitment()
+	if err = filter.HandleCommitment(commitment.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	forged := uint64(0)
+	relay := func(packet []byte) error {
+		if rng.Intn(4) == 0 {
+			// Race a forged copy ahead of the genuine packet.
+			forgery := append([]byte(nil), packet...)
+			forgery[receiver.HeaderSize+4+rng.Intn(500)] ^= 0x80
+			_ = filter.HandlePacket(forgery)
+			forged++
+		}
+		return filter.HandlePacket(packet)
+	}
+	s := sender.New(enc, schedule.New(enc, schedule.Config{
+		Redundancy: redundancy,
+	}), committer.SendFunc(relay), sender.Config{
+		Header: receiver.HeaderFor(42, enc).Bytes(),
+	})
+	if err = s.Run(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if filter.Dropped() != forged {
+		t.Errorf("Dropped() = %d, want %d", filter.Dropped(), forged)
+	}
+	select {
+	case got := <-delivered:
+		if !bytes.Equal(got, object) {
+			t.Error("recovered object mismatch")
+		}
+	case <-time.After(10 * time.Second):
+		t.Error("source object not recovered")
+	}
+}
+
+func TestUncommittedSymbol(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := defaults.ReedSolomonEncoderFactory().New(
+		make([]byte, 1000), 100, 100, 10000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	committer, err := NewCommitter(enc, 1, 0, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := committer.NumCommitted(0); n != 10 {
+		t.Errorf("NumCommitted(0) = %d, want 10", n)
+	}
+	if _, err = committer.Proof(0, 10); err == nil {
+		t.Error("repair symbol proven without being committed")
+	}
+}
+
+func TestSendFuncCopies(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := defaults.ReedSolomonEncoderFactory().New(
+		make([]byte, 1000), 100, 100, 10000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	committer, err := NewCommitter(enc, 1, 0, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Retain every packet, which SendFunc allows, and verify them once all
+	// have been sent.
+	var sent [][]byte
+	s := sender.New(enc, schedule.New(enc, schedule.Config{}),
+		committer.SendFunc(func(packet []byte) error {
+			sent = append(sent, packet)
+			return nil
+		}), sender.Config{Header: receiver.HeaderFor(1, enc).Bytes()})
+	if err = s.Run(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	passed := 0
+	filter, err := NewFilter(pub, func(packet []byte) error {
+		passed++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment := committer.Commitment()
+	if err = filter.HandleCommitment(commitment.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	for _, packet := range sent {
+		_ = filter.HandlePacket(packet)
+	}
+	if passed != 10 || filter.Dropped() != 0 {
+		t.Errorf("%d packets passed, %d dropped, want 10 and 0",
+			passed, filter.Dropped())
+	}
+}
@@ -0,0 +1,180 @@
+// Package merkle commits to the encoding symbols of a source object with a
+// Merkle tree whose root the sender signs once, so that receivers can reject
+// forged symbols from relays they do not trust, without sharing keys with
+// them.
+//
+// The sender commits to a bounded number of encoding symbols of each source
+// block: all source symbols and the first few repair symbols.  Each source
+// block has a tree over its committed encoding symbols, and the source object
+// has a tree over the source block roots:
+//
+//	                  root
+//	               /        \
+//	      block 0 root     block 1 root     (object tree)
+//	       /       \          /     \
+//	     ...       ...      ...     ...     (block trees)
+//	    /   \
+//	ESI 0   ESI 1 ...
+//
+// Each tree is padded with all-zero nodes to a power of two leaves.  The leaf
+// for an encoding symbol is SHA-256(0x00 || SBN || ESI || symbol), with the
+// SBN in 8 bits and the ESI in 32 bits, and an inner node is SHA-256(0x01 ||
+// left || right).  The inclusion proof of an encoding symbol consists of the
+// sibling nodes along its path up to the root.
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// HashSize is the size of tree nodes, in octets.
+const HashSize = sha256.Size
+
+// Hash is a tree node.
+type Hash [HashSize]byte
+
+// Leaf returns the leaf node for the given encoding symbol.
+func Leaf(sbn uint8, esi uint32, symbol []byte) (h Hash) {
+	var prefix [6]byte
+	prefix[1] = sbn
+	binary.BigEndian.PutUint32(prefix[2:], esi)
+	d := sha256.New()
+	d.Write(prefix[:])
+	d.Write(symbol)
+	d.Sum(h[:0])
+	return
+}
+
+func node(left, right *Hash) (h Hash) {
+	d := sha256.New()
+	d.Write([]byte{1})
+	d.Write(left[:])
+	d.Write(right[:])
+	d.Sum(h[:0])
+	return
+}
+
+// tree is a Merkle tree, stored level by level from the leaves up.
+type tree [][]Hash
+
+func newTree(leaves []Hash) (t tree) {
+	n := 1
+	for n < len(leaves) {
+		n *= 2
+	}
+	level := make([]Hash, n)
+	copy(level, leaves)
+	t = append(t, level)
+	for len(level) > 1 {
+		next := make([]Hash, len(level)/2)
+		for i := range next {
+			next[i] = node(&level[2*i], &level[2*i+1])
+		}
+		t = append(t, next)
+		level = next
+	}
+	return
+}
+
+func (t tree) root() Hash {
+	return t[len(t)-1][0]
+}
+
+func (t tree) depth() int {
+	return len(t) - 1
+}
+
+// path returns the siblings along the path of the given leaf.
+func (t tree) path(index uint32) (path []Hash) {
+	for _, level := range t[:t.depth()] {
+		path = append(path, level[index^1])
+		index /= 2
+	}
+	return
+}
+
+// Proof is an inclusion proof of an encoding symbol.
+//
+// Its wire format is the block tree path followed by the object tree path,
+// leaf side first, then the depths of the block tree and of the object tree,
+// in one octet each:
+//
+//	Block path   (BlockDepth × 32 octets)
+//	Object path  (ObjectDepth × 32 octets)
+//	BlockDepth   (8 bits)
+//	ObjectDepth  (8 bits)
+//
+// The proof comes last in a packet, so receivers parse it from the end.
+type Proof struct {
+	BlockPath  []Hash
+	ObjectPath []Hash
+}
+
+// Size returns the size of the proof in its wire format, in octets.
+func (p *Proof) Size() int {
+	return (len(p.BlockPath)+len(p.ObjectPath))*HashSize + 2
+}
+
+// Append appends the proof in its wire format to b.
+func (p *Proof) Append(b []byte) []byte {
+	for _, path := range [][]Hash{p.BlockPath, p.ObjectPath} {
+		for i := range path {
+			b = append(b, path[i][:]...)
+		}
+	}
+	return append(b, uint8(len(p.BlockPath)), uint8(len(p.ObjectPath)))
+}
+
+// ParseProof parses the proof at the end of b, and returns it along with the
+// preceding octets.
+func ParseProof(b []byte) (p Proof, rest []byte, err error) {
+	if len(b) < 2 {
+		err = errors.New("inclusion proof too short")
+		return
+	}
+	blockDepth, objectDepth := int(b[len(b)-2]), int(b[len(b)-1])
+	if blockDepth > 24 || objectDepth > 8 {
+		err = errors.New("inclusion proof too deep")
+		return
+	}
+	n := len(b) - 2 - (blockDepth+objectDepth)*HashSize
+	if n < 0 {
+		err = errors.New("inclusion proof too short")
+		return
+	}
+	rest, b = b[:n], b[n:]
+	p.BlockPath = make([]Hash, blockDepth)
+	for i := range p.BlockPath {
+		b = b[copy(p.BlockPath[i][:], b):]
+	}
+	p.ObjectPath = make([]Hash, objectDepth)
+	for i := range p.ObjectPath {
+		b = b[copy(p.ObjectPath[i][:], b):]
+	}
+	return
+}
+
+// Verify returns whether the proof shows the given encoding symbol to be
+// committed to by the given root.
+func (p *Proof) Verify(root *Hash, sbn uint8, esi uint32, symbol []byte) bool {
+	if uint64(esi) >= 1<<uint(len(p.BlockPath)) ||
+		uint64(sbn) >= 1<<uint(len(p.ObjectPath)) {
+		return false
+	}
+	h := Leaf(sbn, esi, symbol)
+	climb := func(path []Hash, index uint32) {
+		for i := range path {
+			if index&1 == 0 {
+				h = node(&h, &path[i])
+			} else {
+				h = node(&path[i], &h)
+			}
+			index /= 2
+		}
+	}
+	climb(p.BlockPath, esi)
+	climb(p.ObjectPath, uint32(sbn))
+	return h == *root
+}
@@ -0,0 +1,184 @@
+// Package nack provides negative acknowledgements, through which receivers ask
+// the sender to retransmit the exact source symbols they are missing, and an
+// encoder-side responder that answers them.
+//
+// On low-loss links, retransmitting the few source symbols missing is cheaper
+// than having receivers decode from repair symbols.
+package nack
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+)
+
+// Range is a range of consecutive source ESIs.
+type Range struct {
+	First uint16
+	Count uint16
+}
+
+// Ranges returns the given ascending ESIs as ranges of consecutive ESIs.
+func Ranges(esis []uint32) (ranges []Range) {
+	for _, esi := range esis {
+		if n := len(ranges); n > 0 {
+			last := &ranges[n-1]
+			if uint32(last.First)+uint32(last.Count) == esi &&
+				last.Count < 1<<16-1 {
+				last.Count++
+				continue
+			}
+		}
+		ranges = append(ranges, Range{First: uint16(esi), Count: 1})
+	}
+	return
+}
+
+// BlockReport lists the missing source symbols of one source block.
+type BlockReport struct {
+	SBN     uint8
+	Missing []Range
+}
+
+// Message is a missing-ESI report.  Its wire format is, in network byte
+// order:
+//
+//	Object ID                (64 bits)
+//	Number of blocks         (16 bits)
+//
+// and, for each block:
+//
+//	Source Block Number      (8 bits)
+//	Number of ranges         (16 bits)
+//
+// followed, for each range, by:
+//
+//	First ESI                (16 bits)
+//	Count                    (16 bits)
+//
+// Source ESIs fit in 16 bits, as a source block has at most 56403 source
+// symbols.
+type Message struct {
+	ObjectID receiver.ObjectID
+	Blocks   []BlockReport
+}
+
+const (
+	messageHeaderSize = 10
+	blockHeaderSize   = 3
+	rangeSize         = 4
+)
+
+// Report returns a missing-ESI report of the given decoder, listing the
+// missing source symbols of every source block not yet ready.
+//
+// maxRanges limits the total number of ranges in the report, so that it fits
+// in one packet; the report then covers the lowest-numbered source blocks
+// first.  Zero means no limit.
+func Report(id receiver.ObjectID, dec raptorq.Decoder, maxRanges int) (
+	m *Message, err error,
+) {
+	m = &Message{ObjectID: id}
+	numRanges := 0
+	for sbn := 0; sbn < int(dec.NumSourceBlocks()); sbn++ {
+		var esis []uint32
+		if esis, err = dec.MissingSourceSymbols(uint8(sbn)); err != nil {
+			m = nil
+			return
+		}
+		ranges := Ranges(esis)
+		if len(ranges) == 0 {
+			continue
+		}
+		if maxRanges > 0 && numRanges+len(ranges) > maxRanges {
+			ranges = ranges[:maxRanges-numRanges]
+		}
+		if len(ranges) > 0 {
+			m.Blocks = append(m.Blocks, BlockReport{
+				SBN: uint8(sbn), Missing: ranges,
+			})
+			numRanges += len(ranges)
+		}
+		if maxRanges > 0 && numRanges == maxRanges {
+			break
+		}
+	}
+	return
+}
+
+// MarshalBinary returns the message in its wire format.
+func (m *Message) MarshalBinary() (data []byte, err error) {
+	if len(m.Blocks) > 256 {
+		err = errors.New("too many source blocks in missing-ESI report")
+		return
+	}
+	size := messageHeaderSize
+	for _, block := range m.Blocks {
+		if len(block.Missing) > 1<<16-1 {
+			err = errors.New("too many ranges in missing-ESI report")
+			return
+		}
+		size += blockHeaderSize + len(block.Missing)*rangeSize
+	}
+	data = make([]byte, size)
+	binary.BigEndian.PutUint64(data[0:], uint64(m.ObjectID))
+	binary.BigEndian.PutUint16(data[8:], uint16(len(m.Blocks)))
+	b := data[messageHeaderSize:]
+	for _, block := range m.Blocks {
+		b[0] = block.SBN
+		binary.BigEndian.PutUint16(b[1:], uint16(len(block.Missing)))
+		b = b[blockHeaderSize:]
+		for _, r := range block.Missing {
+			binary.BigEndian.PutUint16(b[0:], r.First)
+			binary.BigEndian.PutUint16(b[2:], r.Count)
+			b = b[rangeSize:]
+		}
+	}
+	return
+}
+
+// UnmarshalBinary parses a message in its wire format.
+func (m *Message) UnmarshalBinary(data []byte) (err error) {
+	tooShort := errors.New("missing-ESI report too short")
+	if len(data) < messageHeaderSize {
+		return tooShort
+	}
+	id := receiver.ObjectID(binary.BigEndian.Uint64(data[0:]))
+	numBlocks := int(binary.BigEndian.Uint16(data[8:]))
+	if numBlocks > 256 {
+		return errors.New("too many source blocks in missing-ESI report")
+	}
+	data = data[messageHeaderSize:]
+	// Check the declared counts against the data before allocating for them.
+	if len(data) < numBlocks*blockHeaderSize {
+		return tooShort
+	}
+	blocks := make([]BlockReport, numBlocks)
+	for i := range blocks {
+		if len(data) < blockHeaderSize {
+			return tooShort
+		}
+		blocks[i].SBN = data[0]
+		numRanges := int(binary.BigEndian.Uint16(data[1:]))
+		data = data[blockHeaderSize:]
+		if len(data) < numRanges*rangeSize {
+			return tooShort
+		}
+		ranges := make([]Range, numRanges)
+		for j := range ranges {
+			ranges[j] = Range{
+				First: binary.BigEndian.Uint16(data[0:]),
+				Count: binary.BigEndian.Uint16(data[2:]),
+			}
+			data = data[rangeSize:]
+		}
+		blocks[i].Missing = ranges
+	}
+	if len(blocks) == 0 {
+		blocks = nil
+	}
+	m.ObjectID, m.Blocks = id, blocks
+	return
+}
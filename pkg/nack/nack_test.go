@@ -0,0 +1,179 @@
+package nack
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+func TestRanges(t *testing.T) {
+	got := Ranges([]uint32{0, 1, 2, 5, 7, 8})
+	want := []Range{{0, 3}, {5, 1}, {7, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Ranges() = %v, want %v", got, want)
+	}
+	if got := Ranges(nil); got != nil {
+		t.Errorf("Ranges(nil) = %v, want nil", got)
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	for _, m := range []*Message{
+		{ObjectID: 42},
+		{ObjectID: 1<<64 - 1, Blocks: []BlockReport{
+			{SBN: 0, Missing: []Range{{0, 3}, {5, 1}}},
+			{SBN: 3, Missing: []Range{{56402, 1}}},
+		}},
+	} {
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m1 Message
+		if err = m1.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*m, m1) {
+			t.Errorf("round trip of %+v returned %+v", *m, m1)
+		}
+		if err = m1.UnmarshalBinary(data[:len(data)-1]); err == nil {
+			t.Errorf("truncated report %+v parsed", *m)
+		}
+	}
+}
+
+func TestUnmarshalDeclaredCounts(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"257 blocks", []byte{0, 0, 0, 0, 0, 0, 0, 42, 1, 1}},
+		{"65535 blocks", []byte{0, 0, 0, 0, 0, 0, 0, 42, 0xff, 0xff}},
+		{"blocks without headers", []byte{0, 0, 0, 0, 0, 0, 0, 42, 0, 2, 0, 0, 0}},
+		{"65535 ranges", []byte{0, 0, 0, 0, 0, 0, 0, 42, 0, 1, 0, 0xff, 0xff}},
+	} {
+		var m Message
+		if err := m.UnmarshalBinary(c.data); err == nil {
+			t.Errorf("%s: report parsed", c.name)
+		}
+	}
+}
+
+func TestRetransmission(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	object := make([]byte, 100000)
+	rng.Read(object)
+	enc, err := defaults.ReedSolomonEncoderFactory().New(object,
+		1000, 1000, 30000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	dec, err := defaults.ReedSolomonDecoderFactory().New(enc.CommonOTI(),
+		enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+
+	send := func(sbn uint8, esi uint32) {
+		buf := make([]byte, enc.SymbolSize())
+		if _, err := enc.Encode(sbn, esi, buf); err != nil {
+			t.Fatal(err)
+		}
+		if rng.Float64() >= 0.05 {
+			dec.Decode(sbn, esi, buf)
+		}
+	}
+	for sbn := uint8(0); sbn < enc.NumSourceBlocks(); sbn++ {
+		for esi := uint32(0); esi < uint32(enc.NumSourceSymbols(sbn)); esi++ {
+			send(sbn, esi)
+		}
+	}
+
+	r := NewResponder(7, enc)
+	for round := 0; ; round++ {
+		if round > 100 {
+			t.Fatal("source symbols still missing")
+		}
+		m, err := Report(7, dec, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(m.Blocks) == 0 {
+			break
+		}
+		// Reports from two receivers missing the same symbols.
+		for i := 0; i < 2; i++ {
+			if err = r.HandleMessage(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for {
+			e, ok := r.Next()
+			if !ok {
+				break
+			}
+			send(e.SBN, e.ESI)
+		}
+	}
+	ready := make(chan uint8, enc.NumSourceBlocks())
+	if err := dec.AddReadyBlockChan(ready); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < int(enc.NumSourceBlocks()); i++ {
+		select {
+		case <-ready:
+		case <-time.After(10 * time.Second):
+			t.Fatal("decoder did not recover the object")
+		}
+	}
+	buf := make([]byte, dec.TransferLength())
+	if _, err := dec.SourceObject(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, object) {
+		t.Fatal("recovered object mismatch")
+	}
+	if m, err := Report(7, dec, 0); err != nil || len(m.Blocks) != 0 {
+		t.Errorf("Report() of a ready decoder = %+v, %v", m, err)
+	}
+}
+
+func TestReportMaxRanges(t *testing.T) {
+	enc, err := defaults.ReedSolomonEncoderFactory().New(make([]byte,
+		10000), 100, 100, 3000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	dec, err := defaults.ReedSolomonDecoderFactory().New(enc.CommonOTI(),
+		enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	buf := make([]byte, enc.SymbolSize())
+	for esi := uint32(0); esi < uint32(enc.NumSourceSymbols(0)); esi += 2 {
+		if _, err := enc.Encode(0, esi, buf); err != nil {
+			t.Fatal(err)
+		}
+		dec.DecodeBatch([]raptorq.Symbol{{SBN: 0, ESI: esi, Data: buf}})
+	}
+	m, err := Report(1, dec, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numRanges := 0
+	for _, block := range m.Blocks {
+		numRanges += len(block.Missing)
+	}
+	if numRanges != 5 || m.Blocks[0].Missing[0] != (Range{1, 1}) {
+		t.Errorf("Report() with 5 ranges = %+v", m)
+	}
+}
@@ -0,0 +1,94 @@
+package nack
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+	"github.com/harmony-one/go-raptorq/pkg/schedule"
+)
+
+// Responder answers missing-ESI reports by queueing the source symbols they
+// list for retransmission.
+//
+// Responder implements schedule.Iterator; a sender.Sender run over it sends
+// the queued source symbols and stops once the queue is empty, so the sender
+// is run again for each batch of reports.  Source symbols already queued are
+// not queued twice, so reports from many receivers missing the same source
+// symbols are answered with one retransmission.
+type Responder struct {
+	id     receiver.ObjectID
+	enc    raptorq.Encoder
+	mutex  sync.Mutex
+	queue  []schedule.Entry
+	queued map[schedule.Entry]bool
+}
+
+// NewResponder returns a new responder for the given encoder, which sends the
+// source object identified by the given object ID.
+func NewResponder(id receiver.ObjectID, enc raptorq.Encoder) *Responder {
+	return &Responder{
+		id:     id,
+		enc:    enc,
+		queued: make(map[schedule.Entry]bool),
+	}
+}
+
+// HandleMessage queues the source symbols listed in the given missing-ESI
+// report.
+//
+// HandleMessage returns an error, without queueing anything, if the report is
+// about another source object or lists a source symbol out of range.
+func (r *Responder) HandleMessage(m *Message) (err error) {
+	if m.ObjectID != r.id {
+		err = errors.New("missing-ESI report for another source object")
+		return
+	}
+	for _, block := range m.Blocks {
+		numSourceSymbols := uint32(r.enc.NumSourceSymbols(block.SBN))
+		for _, rng := range block.Missing {
+			if uint32(rng.First)+uint32(rng.Count) > numSourceSymbols {
+				err = errors.New("source symbol out of range")
+				return
+			}
+		}
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, block := range m.Blocks {
+		for _, rng := range block.Missing {
+			for i := uint32(0); i < uint32(rng.Count); i++ {
+				e := schedule.Entry{
+					SBN: block.SBN, ESI: uint32(rng.First) + i,
+				}
+				if !r.queued[e] {
+					r.queued[e] = true
+					r.queue = append(r.queue, e)
+				}
+			}
+		}
+	}
+	return
+}
+
+// Len returns the number of source symbols queued.
+func (r *Responder) Len() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.queue)
+}
+
+// Next returns the next source symbol to retransmit, or ok = false if the
+// queue is empty.
+func (r *Responder) Next() (e schedule.Entry, ok bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.queue) == 0 {
+		return
+	}
+	e, ok = r.queue[0], true
+	r.queue = r.queue[1:]
+	delete(r.queued, e)
+	return
+}
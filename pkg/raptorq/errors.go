@@ -0,0 +1,23 @@
+package raptorq
+
+import "fmt"
+
+// SourceBlockNotReady signals the given source block has not been recovered
+// yet.
+type SourceBlockNotReady uint8
+
+func (e SourceBlockNotReady) Error() string {
+	return fmt.Sprintf("source block %d not ready", uint8(e))
+}
+
+// SourceSymbolNotReady signals the given source symbol has been neither
+// received nor recovered yet.
+type SourceSymbolNotReady struct {
+	SBN uint8
+	ESI uint32
+}
+
+func (e SourceSymbolNotReady) Error() string {
+	return fmt.Sprintf("source symbol %d of source block %d not ready",
+		e.ESI, e.SBN)
+}
@@ -3,6 +3,11 @@
 
 package raptorq
 
+import (
+	"context"
+	"io"
+)
+
 // ObjectInfo provides various codec information about the source object.
 type ObjectInfo interface {
 	// CommonOTI returns the Common FEC Object Transmission Information.
@@ -124,6 +129,47 @@ type Decoder interface {
 	// Use AddReadyBlockChan if immediate notification is needed.
 	Decode(sbn uint8, esi uint32, symbol []byte)
 
+	// DecodeBatch decodes the given received encoding symbols at once, and
+	// returns the status of each symbol, in the same order.
+	//
+	// DecodeBatch is equivalent to calling Decode for each symbol in turn,
+	// but lets implementations amortize per-call overhead; it is meant for
+	// receivers that pull many packets off the network at once.
+	DecodeBatch(symbols []Symbol) (status []DecodeStatus)
+
+	// EndOfInput tells the decoder that no more encoding symbols will be fed
+	// for the given source block, and returns, for each source symbol in the
+	// block, whether the decoder knows it (either received or recovered).
+	//
+	// If fillZeros is true, source symbols that the decoder does not know are
+	// filled with zeros, so that the rest of the source block can still be
+	// retrieved with SourceBlock.  The known bitmap tells which parts of the
+	// retrieved source block are genuine.
+	//
+	// After EndOfInput, the decoder may reject further symbols for the given
+	// source block.
+	//
+	// EndOfInput returns an error if sbn is out of range.
+	EndOfInput(sbn uint8, fillZeros bool) (known []bool, err error)
+
+	// NumReceivedSymbols returns the number of encoding symbols the decoder
+	// has accepted so far for the given source block, that is, excluding
+	// symbols rejected or not needed, or 0 if sbn is out of range.
+	//
+	// Receivers use this to report decoding progress back to the sender.
+	NumReceivedSymbols(sbn uint8) uint32
+
+	// MissingSourceSymbols returns, in ascending order, the ESIs of the
+	// source symbols of the given source block that the decoder has not
+	// received, or none if the source block is ready.
+	//
+	// Receivers on low-loss links use this to ask the sender to retransmit
+	// the exact source symbols missing, instead of waiting for repair
+	// symbols.
+	//
+	// MissingSourceSymbols returns an error if sbn is out of range.
+	MissingSourceSymbols(sbn uint8) (esis []uint32, err error)
+
 	// IsSourceBlockReady returns whether the given source block has been fully
 	// decoded and ready to be retrieved, or false if sbn is out of range.
 	IsSourceBlockReady(sbn uint8) bool
@@ -137,16 +183,59 @@ type Decoder interface {
 	// SourceBlockSize(sbn) to get the required size).
 	SourceBlock(sbn uint8, buf []byte) (n int, err error)
 
+	// SourceSymbol copies the given source symbol into the given buffer.  buf
+	// should contain enough space to store one symbol (use SymbolSize() to get
+	// the required size).  esi must be less than NumSourceSymbols(sbn).
+	//
+	// SourceSymbol succeeds as soon as the source symbol is available, either
+	// received as is or recovered, even if the rest of its source block is
+	// not.  Otherwise it returns a SourceSymbolNotReady error.
+	//
+	// The last source symbol of a source block may contain padding beyond the
+	// end of the source block.
+	SourceSymbol(sbn uint8, esi uint32, buf []byte) (n int, err error)
+
 	// SourceObject copies the source object into the given buffer.  buf should
 	// contain enough space to store the entire source object (use
 	// TransferLength() to get the required size).
 	SourceObject(buf []byte) (n int, err error)
 
+	// ReadAt reads len(p) octets of the source object into p, starting at
+	// octet offset off, as specified by io.ReaderAt.
+	//
+	// Unlike SourceObject, ReadAt does not need the entire source object to
+	// be ready; it succeeds as long as the source blocks, or the source
+	// symbols, covering the requested range are available.  Otherwise it
+	// returns a SourceBlockNotReady error naming the first source block not
+	// yet available, so that the caller can retry the read later.
+	ReadAt(p []byte, off int64) (n int, err error)
+
+	// Reader returns a reader of the source object, which yields source blocks
+	// strictly in source block number order.  The reader blocks until the
+	// next source block becomes ready, or until ctx is done.
+	//
+	// The reader frees each source block once it has been read out of the
+	// decoder, so the decoder should not be used for retrieving source blocks
+	// behind the reader.
+	Reader(ctx context.Context) io.Reader
+
 	// Free, on supported implementations, will free memory used for generating
 	// encoding symbols for the given source block.  Once a source block has
 	// been freed, calling Encode with its SBN may return an error.
 	FreeSourceBlock(sbn uint8)
 
+	// Reencoder returns an Encoder with the same object information as the
+	// decoder, which generates encoding symbols of any ESI, source or repair,
+	// for the source blocks the decoder has recovered.  This lets a relay
+	// that has recovered a source block send brand new repair symbols for it,
+	// instead of forwarding copies of the symbols it has received.
+	//
+	// The returned Encoder reads recovered source blocks from the decoder as
+	// needed; for a source block not yet recovered, its Encode returns a
+	// SourceBlockNotReady error, and its MaxSymbols returns 0.  It must be
+	// closed separately, before the decoder is closed.
+	Reencoder() (enc Encoder, err error)
+
 	// AddReadyBlockChan adds a channel through which the decoder shall avail
 	// source blocks ready for retrieval as soon as they become available.
 	//
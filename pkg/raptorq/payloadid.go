@@ -0,0 +1,39 @@
+package raptorq
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// FECPayloadIDSize is the size of the RaptorQ FEC Payload ID, in octets.
+const FECPayloadIDSize = 4
+
+// MaxESI is the largest encoding symbol ID that fits in a FEC Payload ID.
+const MaxESI = 1<<24 - 1
+
+// PutFECPayloadID writes the FEC Payload ID for the given source block number
+// and encoding symbol ID into b, as specified in RFC 6330 Section 3.2.
+// b must have at least FECPayloadIDSize octets.
+//
+// PutFECPayloadID returns an error if esi exceeds MaxESI, rather than
+// truncating it into a different ESI.
+func PutFECPayloadID(b []byte, sbn uint8, esi uint32) (err error) {
+	if esi > MaxESI {
+		err = errors.New("encoding symbol ID out of range")
+		return
+	}
+	binary.BigEndian.PutUint32(b, uint32(sbn)<<24|esi)
+	return
+}
+
+// ParseFECPayloadID parses the FEC Payload ID at the beginning of b.
+func ParseFECPayloadID(b []byte) (sbn uint8, esi uint32, err error) {
+	if len(b) < FECPayloadIDSize {
+		err = errors.New("FEC Payload ID too short")
+		return
+	}
+	v := binary.BigEndian.Uint32(b)
+	sbn = uint8(v >> 24)
+	esi = v & MaxESI
+	return
+}
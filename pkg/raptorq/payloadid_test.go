@@ -0,0 +1,20 @@
+package raptorq
+
+import "testing"
+
+func TestFECPayloadID(t *testing.T) {
+	b := make([]byte, FECPayloadIDSize)
+	if err := PutFECPayloadID(b, 7, MaxESI); err != nil {
+		t.Fatal(err)
+	}
+	sbn, esi, err := ParseFECPayloadID(b)
+	if err != nil || sbn != 7 || esi != MaxESI {
+		t.Errorf("ParseFECPayloadID() = %d, %d, %v", sbn, esi, err)
+	}
+	if err := PutFECPayloadID(b, 7, MaxESI+1); err == nil {
+		t.Error("out-of-range ESI accepted")
+	}
+	if _, _, err := ParseFECPayloadID(b[:3]); err == nil {
+		t.Error("short FEC Payload ID accepted")
+	}
+}
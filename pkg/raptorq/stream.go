@@ -0,0 +1,139 @@
+package raptorq
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// RepairID identifies a repair symbol of a sliding-window code: the encoding
+// window of source symbols it covers, and the parameters from which the
+// coefficients of its linear combination derive.
+type RepairID struct {
+	// FirstESI is the ESI of the first source symbol of the encoding window.
+	FirstESI uint32
+
+	// NumSourceSymbols is the number of source symbols in the encoding
+	// window.
+	NumSourceSymbols uint16
+
+	// Key is the repair key, which seeds the coefficient generator.
+	Key uint16
+
+	// Density is the density threshold DT, from 0 to 15: each coefficient
+	// is non-zero with probability (DT+1)/16.
+	Density uint8
+}
+
+// RepairIDSize is the size of the Repair FEC Payload ID, in octets.
+const RepairIDSize = 8
+
+// MaxWindowSize is the largest encoding window, in source symbols, that fits
+// in a Repair FEC Payload ID.
+const MaxWindowSize = 1<<12 - 1
+
+// PutRepairID writes the Repair FEC Payload ID of RFC 8681 Section 4.1.3 for
+// the given repair symbol into b, which must have at least RepairIDSize
+// octets:
+//
+//	Repair_Key                 (16 bits)
+//	DT                         (4 bits)
+//	NSS                        (12 bits)
+//	FSS_ESI                    (32 bits)
+func PutRepairID(b []byte, id RepairID) {
+	binary.BigEndian.PutUint16(b[0:], id.Key)
+	binary.BigEndian.PutUint16(b[2:],
+		uint16(id.Density&0xF)<<12|id.NumSourceSymbols&MaxWindowSize)
+	binary.BigEndian.PutUint32(b[4:], id.FirstESI)
+}
+
+// ParseRepairID parses the Repair FEC Payload ID at the beginning of b.
+func ParseRepairID(b []byte) (id RepairID, err error) {
+	if len(b) < RepairIDSize {
+		err = errors.New("Repair FEC Payload ID too short")
+		return
+	}
+	v := binary.BigEndian.Uint16(b[2:])
+	id = RepairID{
+		FirstESI:         binary.BigEndian.Uint32(b[4:]),
+		NumSourceSymbols: v & MaxWindowSize,
+		Key:              binary.BigEndian.Uint16(b[0:]),
+		Density:          uint8(v >> 12),
+	}
+	return
+}
+
+// StreamEncoder is a sliding-window encoder.  Unlike Encoder, it does not
+// need the whole source object up front: source symbols are added to the
+// encoding window one at a time as they are produced, and each repair symbol
+// protects the source symbols in the window at the time it is generated, so
+// that the latency of recovering a lost source symbol is bounded by the window
+// size rather than by a source block.
+type StreamEncoder interface {
+	// SymbolSize returns the size of each source and repair symbol, in
+	// octets.
+	SymbolSize() uint16
+
+	// WindowSize returns the maximum number of source symbols in the
+	// encoding window.
+	WindowSize() uint16
+
+	// AddSourceSymbol adds the given source symbol to the encoding window,
+	// sliding the oldest source symbol out if the window is full, and
+	// returns the ESI of the source symbol.  The symbol must be exactly
+	// SymbolSize() octets; the encoder keeps a copy.
+	AddSourceSymbol(symbol []byte) (esi uint32, err error)
+
+	// RepairSymbol writes a new repair symbol protecting the current
+	// encoding window into buf, which must have at least SymbolSize()
+	// octets, and returns its repair ID.  RepairSymbol returns an error if
+	// the encoding window is empty.
+	RepairSymbol(buf []byte) (id RepairID, err error)
+
+	// Close closes the StreamEncoder.  After a StreamEncoder is closed,
+	// all methods but Close() will panic if called.
+	Close() error
+}
+
+// StreamDecoder is a sliding-window decoder, the counterpart of
+// StreamEncoder.
+//
+// Decoding is done synchronously: each call returns the ESIs of the source
+// symbols it allowed to recover, which can then be retrieved right away.
+type StreamDecoder interface {
+	// SymbolSize returns the size of each source and repair symbol, in
+	// octets.
+	SymbolSize() uint16
+
+	// DecodeSourceSymbol decodes a received source symbol, and returns the
+	// ESIs of the source symbols newly recovered thanks to it, not
+	// including esi itself.
+	DecodeSourceSymbol(esi uint32, symbol []byte) (recovered []uint32)
+
+	// DecodeRepairSymbol decodes a received repair symbol, and returns the
+	// ESIs of the source symbols newly recovered thanks to it.
+	DecodeRepairSymbol(id RepairID, symbol []byte) (recovered []uint32)
+
+	// SourceSymbol copies the given source symbol, received or recovered,
+	// into the given buffer, or returns a SourceSymbolNotReady error if it
+	// is not available, either not yet known or already slid out of the
+	// decoding window.
+	SourceSymbol(esi uint32, buf []byte) (n int, err error)
+
+	// Close closes the StreamDecoder.  After a StreamDecoder is closed, all
+	// methods but Close() will panic if called.
+	Close() error
+}
+
+// StreamEncoderFactory is a factory of StreamEncoder instances.
+type StreamEncoderFactory interface {
+	// New returns a new StreamEncoder for source symbols of the given size,
+	// with an encoding window of up to windowSize source symbols.
+	New(symbolSize uint16, windowSize uint16) (StreamEncoder, error)
+}
+
+// StreamDecoderFactory is a factory of StreamDecoder instances.
+type StreamDecoderFactory interface {
+	// New returns a new StreamDecoder for symbols of the given size, for
+	// encoding windows of up to windowSize source symbols.
+	New(symbolSize uint16, windowSize uint16) (StreamDecoder, error)
+}
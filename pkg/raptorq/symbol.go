@@ -0,0 +1,43 @@
+package raptorq
+
+// Symbol is an encoding symbol along with its identifying source block number
+// and encoding symbol ID.
+type Symbol struct {
+	// SBN is the source block number.
+	SBN uint8
+
+	// ESI is the encoding symbol ID within the source block.
+	ESI uint32
+
+	// Data is the encoding symbol itself.
+	Data []byte
+}
+
+// DecodeStatus is the outcome of feeding one encoding symbol into a Decoder.
+type DecodeStatus uint8
+
+const (
+	// SymbolAccepted means the decoder took the symbol as new input.
+	SymbolAccepted DecodeStatus = iota
+
+	// SymbolNotNeeded means the decoder already had the symbol, or no longer
+	// needs symbols for its source block, e.g. because the source block has
+	// already been recovered.
+	SymbolNotNeeded
+
+	// SymbolRejected means the symbol was invalid, e.g. its source block
+	// number was out of range or its size did not match the symbol size.
+	SymbolRejected
+)
+
+func (s DecodeStatus) String() string {
+	switch s {
+	case SymbolAccepted:
+		return "accepted"
+	case SymbolNotNeeded:
+		return "not needed"
+	case SymbolRejected:
+		return "rejected"
+	}
+	return "unknown"
+}
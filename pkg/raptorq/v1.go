@@ -0,0 +1,223 @@
+package raptorq
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/harmony-one/go-raptorq/internal/rfc6330"
+)
+
+// The RaptorQ__v1 API of libRaptorQ encodes one block at a time, of a number
+// of source symbols chosen from the Block_Size table, and leaves splitting an
+// object into blocks to the caller.  Objects encoded this way are not RFC 6330
+// objects: they may have up to 2^32 blocks, and are described by V1Metadata
+// instead of OTIs.
+
+// V1BlockSizes returns the supported numbers of source symbols per block, in
+// ascending order; the Block_Size table of libRaptorQ, which is also K′ of
+// RFC 6330 Table 2.
+func V1BlockSizes() []uint16 {
+	return append([]uint16(nil), rfc6330.KPrimes[:]...)
+}
+
+// V1BlockSize returns the smallest supported number of source symbols per
+// block that is not less than k, or 0 if k is larger than all.
+func V1BlockSize(k uint32) uint16 {
+	return rfc6330.KPrime(k)
+}
+
+// V1MetadataVersion is the version of the V1Metadata format.
+const V1MetadataVersion = 1
+
+// V1MetadataSize is the size of the V1Metadata format, in octets.
+const V1MetadataSize = 16
+
+// V1Metadata describes an object encoded with the RaptorQ__v1 API, which the
+// receiver needs in order to decode it.
+//
+// The object is split into blocks of BlockSymbols source symbols of
+// SymbolSize octets each; the last block is zero-padded.
+//
+// V1Metadata is marshaled as:
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|    Version    |   Reserved    |          Symbol Size          |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|         Block Symbols         |           Reserved            |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                                                               |
+//	+                        Transfer Length                        +
+//	|                                                               |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+// Version is V1MetadataVersion; receivers reject other versions.  Reserved
+// fields are zero.
+type V1Metadata struct {
+	// TransferLength is the object size, in octets.
+	TransferLength uint64
+
+	// SymbolSize is the symbol size, in octets.
+	SymbolSize uint16
+
+	// BlockSymbols is the number of source symbols per block, one of
+	// V1BlockSizes.
+	BlockSymbols uint16
+}
+
+// Validate returns an error if the metadata is out of range.
+func (m V1Metadata) Validate() error {
+	switch {
+	case m.TransferLength == 0:
+		return errors.New("empty object")
+	case m.SymbolSize == 0:
+		return errors.New("symbol size must not be zero")
+	case V1BlockSize(uint32(m.BlockSymbols)) != m.BlockSymbols:
+		return errors.New("block size not in the Block_Size table")
+	case m.NumBlocks() > 1<<32:
+		return errors.New("too many blocks")
+	}
+	return nil
+}
+
+// BlockSize returns the size of a full block, in octets.
+func (m V1Metadata) BlockSize() uint64 {
+	return uint64(m.BlockSymbols) * uint64(m.SymbolSize)
+}
+
+// NumBlocks returns the number of blocks.
+func (m V1Metadata) NumBlocks() uint64 {
+	return (m.TransferLength + m.BlockSize() - 1) / m.BlockSize()
+}
+
+// SourceBlockSize returns the size of the object data in the given block, in
+// octets, or 0 if block is out of range.
+func (m V1Metadata) SourceBlockSize(block uint32) uint32 {
+	start := uint64(block) * m.BlockSize()
+	if start >= m.TransferLength {
+		return 0
+	}
+	end := start + m.BlockSize()
+	if end > m.TransferLength {
+		end = m.TransferLength
+	}
+	return uint32(end - start)
+}
+
+// MarshalBinary encodes the metadata.
+func (m V1Metadata) MarshalBinary() (data []byte, err error) {
+	data = make([]byte, V1MetadataSize)
+	data[0] = V1MetadataVersion
+	binary.BigEndian.PutUint16(data[2:], m.SymbolSize)
+	binary.BigEndian.PutUint16(data[4:], m.BlockSymbols)
+	binary.BigEndian.PutUint64(data[8:], m.TransferLength)
+	return
+}
+
+// UnmarshalBinary decodes and validates the metadata.
+func (m *V1Metadata) UnmarshalBinary(data []byte) (err error) {
+	switch {
+	case len(data) != V1MetadataSize:
+		err = errors.New("V1 metadata size mismatch")
+	case data[0] != V1MetadataVersion:
+		err = fmt.Errorf("unsupported V1 metadata version %d", data[0])
+	case data[1] != 0 || data[6] != 0 || data[7] != 0:
+		err = errors.New("V1 metadata reserved fields not zero")
+	}
+	if err != nil {
+		return
+	}
+	decoded := V1Metadata{
+		SymbolSize:     binary.BigEndian.Uint16(data[2:]),
+		BlockSymbols:   binary.BigEndian.Uint16(data[4:]),
+		TransferLength: binary.BigEndian.Uint64(data[8:]),
+	}
+	if err = decoded.Validate(); err == nil {
+		*m = decoded
+	}
+	return
+}
+
+// BlockNotReady signals the given block of a V1Decoder has not been recovered
+// yet.
+type BlockNotReady uint32
+
+func (e BlockNotReady) Error() string {
+	return fmt.Sprintf("block %d not ready", uint32(e))
+}
+
+// V1Encoder encodes one object into blocks of encoding symbols with the
+// RaptorQ__v1 API.
+type V1Encoder interface {
+	// Metadata returns the metadata of the object.
+	Metadata() V1Metadata
+
+	// Encode writes the encoding symbol identified by the given block
+	// number and encoding symbol ID into buf, and returns the number of
+	// octets written.
+	Encode(block uint32, esi uint32, buf []byte) (written uint, err error)
+
+	// MaxSymbols returns the number of encoding symbols that can be generated
+	// for the given block, or 0 if block is out of range.
+	MaxSymbols(block uint32) uint32
+
+	// FreeBlock frees the memory used for encoding the given block.  Encode
+	// recreates it as needed.
+	FreeBlock(block uint32)
+
+	// Close closes the encoder.  After an encoder is closed, all methods but
+	// Close will panic if called.
+	Close() error
+}
+
+// V1EncoderFactory is a factory of V1Encoder instances.
+type V1EncoderFactory interface {
+	// New returns an encoder of the given object, split into blocks of
+	// blockSymbols source symbols of symbolSize octets.  blockSymbols must be
+	// one of V1BlockSizes.
+	New(input []byte, symbolSize uint16, blockSymbols uint16) (V1Encoder, error)
+}
+
+// V1Decoder decodes one object encoded with the RaptorQ__v1 API.
+type V1Decoder interface {
+	// Metadata returns the metadata of the object.
+	Metadata() V1Metadata
+
+	// Decode decodes a received encoding symbol, and returns its status.
+	// The block becomes ready, if at all, by the time Decode returns.
+	Decode(block uint32, esi uint32, symbol []byte) DecodeStatus
+
+	// IsBlockReady returns whether the given block has been recovered.
+	// Blocks freed with FreeBlock still count as recovered, though Block
+	// fails for them, and further symbols of them are not needed.
+	IsBlockReady(block uint32) bool
+
+	// IsObjectReady returns whether all blocks have been recovered.
+	IsObjectReady() bool
+
+	// Block copies the object data of the given block into buf, which
+	// should have room for Metadata().SourceBlockSize(block) octets.
+	// Block returns a BlockNotReady error if the block has not been
+	// recovered.
+	Block(block uint32, buf []byte) (n int, err error)
+
+	// Object copies the object into buf, which should have room for
+	// Metadata().TransferLength octets.
+	Object(buf []byte) (n int, err error)
+
+	// FreeBlock frees the memory used for the given block.  The block can
+	// no longer be retrieved.
+	FreeBlock(block uint32)
+
+	// Close closes the decoder.  After a decoder is closed, all methods but
+	// Close will panic if called.
+	Close() error
+}
+
+// V1DecoderFactory is a factory of V1Decoder instances.
+type V1DecoderFactory interface {
+	// New returns a decoder of the object of the given metadata.
+	New(metadata V1Metadata) (V1Decoder, error)
+}
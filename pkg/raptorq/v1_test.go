@@ -0,0 +1,36 @@
+package raptorq
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestV1Metadata(t *testing.T) {
+	m := V1Metadata{TransferLength: 1<<32 + 5, SymbolSize: 1024, BlockSymbols: 101}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if n := m.NumBlocks(); n != 41528 {
+		t.Errorf("NumBlocks() = %d, want 41528", n)
+	}
+	if size := m.SourceBlockSize(41527); size != 78853 {
+		t.Errorf("last SourceBlockSize = %d, want 78853", size)
+	}
+	data, _ := m.MarshalBinary()
+	want := []byte{1, 0, 4, 0, 0, 101, 0, 0, 0, 0, 0, 1, 0, 0, 0, 5}
+	if !bytes.Equal(data, want) {
+		t.Errorf("MarshalBinary() = % x, want % x", data, want)
+	}
+	var decoded V1Metadata
+	if err := decoded.UnmarshalBinary(data); err != nil || decoded != m {
+		t.Errorf("UnmarshalBinary() = %+v, %v", decoded, err)
+	}
+	data[0] = 2
+	if err := decoded.UnmarshalBinary(data); err == nil {
+		t.Error("unknown version accepted")
+	}
+	m.BlockSymbols = 100
+	if err := m.Validate(); err == nil {
+		t.Error("block size outside the Block_Size table accepted")
+	}
+}
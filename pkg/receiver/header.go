@@ -0,0 +1,64 @@
+package receiver
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// ObjectID identifies a source object among those sent to a receiver.
+type ObjectID uint64
+
+// HeaderSize is the size of the object header, in octets.
+const HeaderSize = 20
+
+// Header is the object header that prefixes each packet, in front of the FEC
+// Payload ID.  It identifies the source object the packet belongs to, and
+// advertises the object transmission information needed to decode it.  All
+// fields are in network byte order:
+//
+//	Object ID                (64 bits)
+//	Common FEC OTI           (64 bits)
+//	Scheme-Specific FEC OTI  (32 bits)
+type Header struct {
+	ObjectID          ObjectID
+	CommonOTI         uint64
+	SchemeSpecificOTI uint32
+}
+
+// HeaderFor returns the object header for the given object ID and object
+// information, e.g. an Encoder.
+func HeaderFor(id ObjectID, info raptorq.ObjectInfo) Header {
+	return Header{
+		ObjectID:          id,
+		CommonOTI:         info.CommonOTI(),
+		SchemeSpecificOTI: info.SchemeSpecificOTI(),
+	}
+}
+
+// Put writes the header into b, which must have at least HeaderSize octets.
+func (h Header) Put(b []byte) {
+	binary.BigEndian.PutUint64(b[0:], uint64(h.ObjectID))
+	binary.BigEndian.PutUint64(b[8:], h.CommonOTI)
+	binary.BigEndian.PutUint32(b[16:], h.SchemeSpecificOTI)
+}
+
+// Bytes returns the header in its wire format, e.g. for sender.Config.
+func (h Header) Bytes() []byte {
+	b := make([]byte, HeaderSize)
+	h.Put(b)
+	return b
+}
+
+// ParseHeader parses the header at the beginning of b.
+func ParseHeader(b []byte) (h Header, err error) {
+	if len(b) < HeaderSize {
+		err = errors.New("object header too short")
+		return
+	}
+	h.ObjectID = ObjectID(binary.BigEndian.Uint64(b[0:]))
+	h.CommonOTI = binary.BigEndian.Uint64(b[8:])
+	h.SchemeSpecificOTI = binary.BigEndian.Uint32(b[16:])
+	return
+}
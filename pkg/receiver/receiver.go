@@ -0,0 +1,319 @@
+// Package receiver provides a receiver that demultiplexes packets of many
+// source objects into their own decoders.
+package receiver
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// DeliverFunc is called with each source object recovered.
+type DeliverFunc func(id ObjectID, object []byte)
+
+// Object is a recovered source object.
+type Object struct {
+	ID   ObjectID
+	Data []byte
+}
+
+// DefaultMaxCompleted is the default number of recovered source objects
+// remembered in order to drop late packets for them.
+const DefaultMaxCompleted = 1024
+
+// Config is the receiver configuration.
+type Config struct {
+	// Factory creates decoders for newly seen source objects.
+	Factory raptorq.DecoderFactory
+
+	// Deliver is called with each source object recovered.  It is called
+	// from a goroutine of its own, once per source object.
+	Deliver DeliverFunc
+
+	// Objects, if not nil, receives each source object recovered, after
+	// Deliver is called.  Each source object is sent from a goroutine of its
+	// own, which blocks until the channel takes it.
+	Objects chan<- Object
+
+	// Timeout is how long an incomplete source object is kept without
+	// receiving any packets for it, and how long a recovered source object
+	// is remembered in order to drop late packets for it.  Zero means
+	// forever.
+	Timeout time.Duration
+
+	// MaxCompleted is the maximum number of recovered source objects
+	// remembered in order to drop late packets for them; the least recently
+	// recovered ones are forgotten first.  Zero means DefaultMaxCompleted.
+	MaxCompleted int
+}
+
+// OTIMismatch signals a packet advertised different object transmission
+// information from earlier packets of the same source object.
+type OTIMismatch ObjectID
+
+func (e OTIMismatch) Error() string {
+	return fmt.Sprintf("object %d: object transmission information mismatch",
+		uint64(e))
+}
+
+type object struct {
+	header   Header
+	dec      raptorq.Decoder
+	lastSeen time.Time
+}
+
+// Receiver receives packets of the form:
+//
+//	Header || FEC Payload ID || encoding symbol
+//
+// for many source objects, creates a decoder for each source object when its
+// first packet arrives, and delivers each source object once recovered.
+type Receiver struct {
+	cfg       Config
+	mutex     sync.Mutex
+	objects   map[ObjectID]*object
+	completed map[ObjectID]time.Time
+	// completedOrder lists recovered source objects in order of recovery,
+	// possibly including some forgotten since.
+	completedOrder []completion
+	lastEvict      time.Time
+	closed         bool
+}
+
+type completion struct {
+	id ObjectID
+	at time.Time
+}
+
+// New returns a new receiver.
+func New(cfg Config) (r *Receiver, err error) {
+	if cfg.Factory == nil {
+		err = errors.New("decoder factory required")
+		return
+	}
+	if cfg.MaxCompleted <= 0 {
+		cfg.MaxCompleted = DefaultMaxCompleted
+	}
+	r = &Receiver{
+		cfg:       cfg,
+		objects:   make(map[ObjectID]*object),
+		completed: make(map[ObjectID]time.Time),
+		lastEvict: time.Now(),
+	}
+	return
+}
+
+// HandlePacket handles one received packet.
+func (r *Receiver) HandlePacket(packet []byte) (err error) {
+	h, err := ParseHeader(packet)
+	if err != nil {
+		return
+	}
+	packet = packet[HeaderSize:]
+	sbn, esi, err := raptorq.ParseFECPayloadID(packet)
+	if err != nil {
+		return
+	}
+	symbol := raptorq.Symbol{
+		SBN: sbn, ESI: esi, Data: packet[raptorq.FECPayloadIDSize:],
+	}
+	return r.Receive(h, []raptorq.Symbol{symbol})
+}
+
+// Receive feeds the given encoding symbols of the source object identified by
+// the given header into its decoder.
+func (r *Receiver) Receive(h Header, symbols []raptorq.Symbol) (err error) {
+	now := time.Now()
+	r.mutex.Lock()
+	// Decode under the lock, so that the decoder is not closed meanwhile.
+	dec, err := r.decoder(h, now)
+	if dec != nil && err == nil {
+		dec.DecodeBatch(symbols)
+	}
+	r.mutex.Unlock()
+	r.maybeEvict(now)
+	return
+}
+
+// decoder returns the decoder for the given source object, creating one if
+// needed, or nil if the source object has already been recovered.
+//
+// The caller must hold the mutex.
+func (r *Receiver) decoder(h Header, now time.Time) (
+	dec raptorq.Decoder, err error,
+) {
+	if r.closed {
+		err = errors.New("receiver closed")
+		return
+	}
+	if _, ok := r.completed[h.ObjectID]; ok {
+		return
+	}
+	obj, ok := r.objects[h.ObjectID]
+	if ok {
+		if obj.header != h {
+			err = OTIMismatch(h.ObjectID)
+			return
+		}
+		obj.lastSeen = now
+		dec = obj.dec
+		return
+	}
+	if dec, err = r.cfg.Factory.New(h.CommonOTI, h.SchemeSpecificOTI); err != nil {
+		return
+	}
+	ready := make(chan uint8, dec.NumSourceBlocks())
+	if err = dec.AddReadyBlockChan(ready); err != nil {
+		_ = dec.Close()
+		dec = nil
+		return
+	}
+	obj = &object{header: h, dec: dec, lastSeen: now}
+	r.objects[h.ObjectID] = obj
+	go r.awaitObject(obj, ready)
+	return
+}
+
+// awaitObject waits for all source blocks of the given source object to
+// become ready, then delivers the source object.
+func (r *Receiver) awaitObject(obj *object, ready <-chan uint8) {
+	numSourceBlocks := int(obj.dec.NumSourceBlocks())
+	seen := make(map[uint8]bool, numSourceBlocks)
+	for sbn := range ready {
+		seen[sbn] = true
+		if len(seen) == numSourceBlocks {
+			r.complete(obj)
+			return
+		}
+	}
+	// The decoder has been closed, e.g. evicted.
+}
+
+func (r *Receiver) complete(obj *object) {
+	r.mutex.Lock()
+	id := obj.header.ObjectID
+	if r.objects[id] != obj {
+		r.mutex.Unlock()
+		return
+	}
+	delete(r.objects, id)
+	r.remember(id, time.Now())
+	r.mutex.Unlock()
+	buf := make([]byte, obj.dec.TransferLength())
+	_, err := obj.dec.SourceObject(buf)
+	_ = obj.dec.Close()
+	if err != nil {
+		return
+	}
+	if r.cfg.Deliver != nil {
+		r.cfg.Deliver(id, buf)
+	}
+	if r.cfg.Objects != nil {
+		r.cfg.Objects <- Object{ID: id, Data: buf}
+	}
+}
+
+// remember remembers the given source object as recovered at the given time,
+// forgetting the least recently recovered ones beyond MaxCompleted.
+//
+// The caller must hold the mutex.
+func (r *Receiver) remember(id ObjectID, at time.Time) {
+	r.completed[id] = at
+	r.completedOrder = append(r.completedOrder, completion{id, at})
+	for len(r.completed) > r.cfg.MaxCompleted {
+		c := r.completedOrder[0]
+		r.completedOrder = r.completedOrder[1:]
+		// Skip entries already forgotten, e.g. evicted on timeout.
+		if t, ok := r.completed[c.id]; ok && t.Equal(c.at) {
+			delete(r.completed, c.id)
+		}
+	}
+	if len(r.completedOrder) > 2*r.cfg.MaxCompleted {
+		r.compactCompleted()
+	}
+}
+
+// compactCompleted drops entries of forgotten source objects from the
+// recovery order.
+//
+// The caller must hold the mutex.
+func (r *Receiver) compactCompleted() {
+	order := make([]completion, 0, len(r.completed))
+	for _, c := range r.completedOrder {
+		if t, ok := r.completed[c.id]; ok && t.Equal(c.at) {
+			order = append(order, c)
+		}
+	}
+	r.completedOrder = order
+}
+
+func (r *Receiver) maybeEvict(now time.Time) {
+	if r.cfg.Timeout <= 0 {
+		return
+	}
+	r.mutex.Lock()
+	due := now.Sub(r.lastEvict) >= r.cfg.Timeout/2
+	r.mutex.Unlock()
+	if due {
+		r.Evict(now)
+	}
+}
+
+// Evict closes and discards the decoders of incomplete source objects that
+// have received no packets within the timeout as of the given time, and
+// forgets recovered source objects older than the timeout.
+//
+// Receive calls Evict periodically; call it explicitly to evict stale source
+// objects while no packets arrive.
+func (r *Receiver) Evict(now time.Time) {
+	if r.cfg.Timeout <= 0 {
+		return
+	}
+	var stale []raptorq.Decoder
+	r.mutex.Lock()
+	r.lastEvict = now
+	for id, obj := range r.objects {
+		if now.Sub(obj.lastSeen) >= r.cfg.Timeout {
+			delete(r.objects, id)
+			stale = append(stale, obj.dec)
+		}
+	}
+	for id, t := range r.completed {
+		if now.Sub(t) >= r.cfg.Timeout {
+			delete(r.completed, id)
+		}
+	}
+	r.mutex.Unlock()
+	for _, dec := range stale {
+		_ = dec.Close()
+	}
+}
+
+// NumPending returns the number of source objects being received.
+func (r *Receiver) NumPending() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.objects)
+}
+
+// Close closes the receiver along with the decoders of all source objects
+// being received.
+func (r *Receiver) Close() (err error) {
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		err = errors.New("receiver already closed")
+		return
+	}
+	r.closed = true
+	objects := r.objects
+	r.objects = nil
+	r.mutex.Unlock()
+	for _, obj := range objects {
+		_ = obj.dec.Close()
+	}
+	return
+}
@@ -0,0 +1,200 @@
+package receiver
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// packets returns the packets of the source symbols and of a couple of repair
+// symbols of the given source object, encoded with the Reed-Solomon code.
+func packets(t *testing.T, id ObjectID, object []byte) (
+	header Header, packets [][]byte,
+) {
+	enc, err := defaults.ReedSolomonEncoderFactory().New(object, 10, 10,
+		1000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	header = HeaderFor(id, enc)
+	n := uint32(enc.NumSourceSymbols(0)) + 2
+	for esi := uint32(0); esi < n; esi++ {
+		packet := make([]byte,
+			HeaderSize+raptorq.FECPayloadIDSize+int(enc.SymbolSize()))
+		header.Put(packet)
+		if err = raptorq.PutFECPayloadID(packet[HeaderSize:], 0,
+			esi); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = enc.Encode(0, esi,
+			packet[HeaderSize+raptorq.FECPayloadIDSize:]); err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, packet)
+	}
+	return
+}
+
+func newReceiver(t *testing.T, cfg Config) (r *Receiver, objects chan Object) {
+	objects = make(chan Object, 16)
+	cfg.Factory = defaults.ReedSolomonDecoderFactory()
+	cfg.Objects = objects
+	r, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func expect(t *testing.T, objects <-chan Object, id ObjectID, data []byte) {
+	select {
+	case obj := <-objects:
+		if obj.ID != id || !bytes.Equal(obj.Data, data) {
+			t.Errorf("delivered object %d (%q), want %d (%q)",
+				obj.ID, obj.Data, id, data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("object %d not delivered", id)
+	}
+}
+
+func expectNone(t *testing.T, objects <-chan Object) {
+	select {
+	case obj := <-objects:
+		t.Errorf("unexpected delivery of object %d", obj.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDemultiplexing(t *testing.T) {
+	r, objects := newReceiver(t, Config{})
+	defer r.Close()
+	data1 := []byte("the first source object, a few symbols long")
+	data2 := []byte("and the second one, interleaved with the first")
+	_, packets1 := packets(t, 1, data1)
+	_, packets2 := packets(t, 2, data2)
+	var delivered []ObjectID
+	// Two objects, each recovered from every other packet, interleaved.
+	for i := 0; i < len(packets1) || i < len(packets2); i += 2 {
+		for _, p := range [][][]byte{packets1, packets2} {
+			if i < len(p) {
+				if err := r.HandlePacket(p[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if i+1 < len(p) {
+				if err := r.HandlePacket(p[len(p)-1-i/2]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	for range []int{1, 2} {
+		obj := <-objects
+		delivered = append(delivered, obj.ID)
+		want := map[ObjectID][]byte{1: data1, 2: data2}[obj.ID]
+		if !bytes.Equal(obj.Data, want) {
+			t.Errorf("object %d = %q, want %q", obj.ID, obj.Data, want)
+		}
+	}
+	if len(delivered) != 2 || delivered[0] == delivered[1] {
+		t.Errorf("delivered %v", delivered)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	var calls int
+	r, objects := newReceiver(t, Config{
+		Deliver: func(ObjectID, []byte) { calls++ },
+	})
+	defer r.Close()
+	data := []byte("delivered once, however many packets arrive")
+	_, p := packets(t, 7, data)
+	for i := 0; i < 3; i++ {
+		for _, packet := range p {
+			if err := r.HandlePacket(packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if i == 0 {
+			expect(t, objects, 7, data)
+		}
+	}
+	expectNone(t, objects)
+	if calls != 1 {
+		t.Errorf("Deliver called %d times", calls)
+	}
+	if n := r.NumPending(); n != 0 {
+		t.Errorf("NumPending() = %d after late packets", n)
+	}
+}
+
+func TestOTIMismatch(t *testing.T) {
+	r, _ := newReceiver(t, Config{})
+	defer r.Close()
+	_, p := packets(t, 3, []byte("one source object"))
+	_, q := packets(t, 3, []byte("a different object under the same ID"))
+	if err := r.HandlePacket(p[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.HandlePacket(q[0]).(OTIMismatch); !ok {
+		t.Error("OTI mismatch not detected")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	r, objects := newReceiver(t, Config{Timeout: time.Minute})
+	defer r.Close()
+	data := []byte("this source object never completes")
+	_, p := packets(t, 5, data)
+	if err := r.HandlePacket(p[0]); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.NumPending(); n != 1 {
+		t.Fatalf("NumPending() = %d, want 1", n)
+	}
+	r.Evict(time.Now().Add(30 * time.Second))
+	if n := r.NumPending(); n != 1 {
+		t.Errorf("NumPending() = %d before timeout, want 1", n)
+	}
+	r.Evict(time.Now().Add(2 * time.Minute))
+	if n := r.NumPending(); n != 0 {
+		t.Errorf("NumPending() = %d after timeout, want 0", n)
+	}
+	// The source object starts over.
+	for _, packet := range p[1:] {
+		if err := r.HandlePacket(packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect(t, objects, 5, data)
+}
+
+func TestMaxCompleted(t *testing.T) {
+	r, objects := newReceiver(t, Config{MaxCompleted: 2})
+	defer r.Close()
+	all := make(map[ObjectID][][]byte)
+	for id := ObjectID(1); id <= 3; id++ {
+		data := []byte{byte(id), 1, 2, 3}
+		_, all[id] = packets(t, id, data)
+		for _, packet := range all[id] {
+			if err := r.HandlePacket(packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expect(t, objects, id, data)
+	}
+	// Objects 2 and 3 are still remembered, object 1 is not.
+	for id := ObjectID(3); id >= 1; id-- {
+		if err := r.HandlePacket(all[id][0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := r.NumPending(); n != 1 {
+		t.Errorf("NumPending() = %d, want 1", n)
+	}
+}
@@ -0,0 +1,107 @@
+// Package registry provides a registry of codec implementations, each
+// registered under a name along with its encoder and decoder factories and
+// capabilities.
+//
+// Implementations register themselves when their package is initialized; the
+// defaults package imports those available in the build, and picks one of them
+// as the default.
+package registry
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// Well-known codec names.
+const (
+	// LibRaptorQ is the RaptorQ codec of RFC 6330 backed by libRaptorQ.
+	LibRaptorQ = "libraptorq"
+
+	// ReedSolomon is the Reed-Solomon codec over GF(2^8) of RFC 6865.
+	ReedSolomon = "rs"
+
+	// R10 is the Raptor codec of RFC 5053.
+	R10 = "r10"
+)
+
+// Capabilities describe what a codec can do.
+type Capabilities struct {
+	// FECEncodingID is the FEC Encoding ID of the code, as assigned by
+	// IANA, e.g. 6 for RaptorQ.
+	FECEncodingID uint8
+
+	// MaxSourceSymbols is the largest number of source symbols per source
+	// block.
+	MaxSourceSymbols uint32
+
+	// MaxSymbols is the largest number of encoding symbols per source block,
+	// source symbols included.
+	MaxSymbols uint32
+
+	// MDS tells whether any K encoding symbols recover a source block of K
+	// source symbols.  Otherwise a few more may be needed.
+	MDS bool
+
+	// Cgo tells whether the implementation needs cgo.
+	Cgo bool
+}
+
+// Codec is a registered codec implementation.
+type Codec struct {
+	// Name identifies the implementation, e.g. "libraptorq".
+	Name string
+
+	// Capabilities describe what the codec can do.
+	Capabilities Capabilities
+
+	// EncoderFactory creates encoders of the codec.
+	EncoderFactory raptorq.EncoderFactory
+
+	// DecoderFactory creates decoders of the codec.
+	DecoderFactory raptorq.DecoderFactory
+}
+
+var (
+	mutex  sync.RWMutex
+	codecs = make(map[string]Codec)
+)
+
+// Register registers the given codec under its name.
+//
+// Register panics if the name is empty or already registered, or if either
+// factory is nil.
+func Register(codec Codec) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	switch _, dup := codecs[codec.Name]; {
+	case codec.Name == "":
+		panic("registry: codec name empty")
+	case dup:
+		panic("registry: codec " + codec.Name + " registered twice")
+	case codec.EncoderFactory == nil || codec.DecoderFactory == nil:
+		panic("registry: codec " + codec.Name + " lacks a factory")
+	}
+	codecs[codec.Name] = codec
+}
+
+// Lookup returns the codec registered under the given name, and whether one
+// is.
+func Lookup(name string) (codec Codec, ok bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	codec, ok = codecs[name]
+	return
+}
+
+// Codecs returns all registered codecs, sorted by name.
+func Codecs() (all []Codec) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, codec := range codecs {
+		all = append(all, codec)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return
+}
@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+type encoderFactory struct{}
+
+func (encoderFactory) New([]byte, uint16, uint16, uint32, uint8) (
+	raptorq.Encoder, error,
+) {
+	return nil, nil
+}
+
+type decoderFactory struct{}
+
+func (decoderFactory) New(uint64, uint32) (raptorq.Decoder, error) {
+	return nil, nil
+}
+
+func TestRegister(t *testing.T) {
+	codec := Codec{
+		Name:           "test",
+		Capabilities:   Capabilities{MDS: true},
+		EncoderFactory: encoderFactory{},
+		DecoderFactory: decoderFactory{},
+	}
+	Register(codec)
+	if got, ok := Lookup("test"); !ok || got.Name != "test" || !got.Capabilities.MDS {
+		t.Errorf("Lookup(\"test\") = %+v, %v", got, ok)
+	}
+	if _, ok := Lookup("missing"); ok {
+		t.Error("unregistered codec found")
+	}
+	found := false
+	for _, c := range Codecs() {
+		found = found || c.Name == "test"
+	}
+	if !found {
+		t.Error("registered codec not listed")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate registration did not panic")
+		}
+	}()
+	Register(codec)
+}
@@ -0,0 +1,216 @@
+// Package relay provides a relay that passes encoding symbols of one source
+// object on to downstream peers as it receives them, without waiting for the
+// source object to be recovered.
+package relay
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// SendFunc sends the given encoding symbol to downstream peers.
+//
+// SendFunc must not retain symbol.Data after it returns.
+type SendFunc func(symbol raptorq.Symbol) error
+
+// ESIFunc returns the ESI of the n-th (zero-based) repair symbol that a relay
+// generates for the given source block, whose encoder can generate up to
+// maxSymbols encoding symbols.  It returns ok = false if no more ESIs are
+// available.
+//
+// ESIs returned should not collide with those used by other senders of the
+// same source object, lest receivers get duplicates.
+type ESIFunc func(sbn uint8, n uint32, maxSymbols uint32) (esi uint32, ok bool)
+
+// TopDownESIs allocates repair ESIs downward from the top of the ESI space,
+// i.e. away from original senders, which typically allocate them upward
+// from 0.
+func TopDownESIs(sbn uint8, n uint32, maxSymbols uint32) (
+	esi uint32, ok bool,
+) {
+	if n >= maxSymbols {
+		return
+	}
+	return maxSymbols - 1 - n, true
+}
+
+// Config is the relay configuration.
+type Config struct {
+	// RepairESIs allocates ESIs of repair symbols the relay generates.
+	// If nil, TopDownESIs is used.
+	RepairESIs ESIFunc
+}
+
+// Relay relays encoding symbols of one source object.
+//
+// Until a source block is recovered, the relay forwards each new symbol
+// of the source block that its decoder accepts, dropping duplicates.  Once
+// the source block is recovered, the relay instead generates a brand new
+// repair symbol for each further symbol it receives for the source block,
+// so that downstream peers keep getting useful symbols at the same rate.
+// The relay stops sending symbols of a source block once downstream peers
+// signal completion of the source block with Complete.
+type Relay struct {
+	dec        raptorq.Decoder
+	send       SendFunc
+	repairESIs ESIFunc
+	mutex      sync.Mutex
+	reencoder  raptorq.Encoder
+	generated  []uint32
+	complete   []bool
+	remaining  int
+	done       chan struct{}
+	closed     bool
+}
+
+// New returns a new relay, which feeds received symbols into the given
+// decoder and sends symbols to downstream peers using the given function.
+//
+// cfg may be nil, in which case the default configuration is used.
+//
+// The relay does not take ownership of the decoder; the caller should close
+// the decoder after closing the relay.
+func New(dec raptorq.Decoder, send SendFunc, cfg *Config) *Relay {
+	numSourceBlocks := int(dec.NumSourceBlocks())
+	r := &Relay{
+		dec:        dec,
+		send:       send,
+		repairESIs: TopDownESIs,
+		generated:  make([]uint32, numSourceBlocks),
+		complete:   make([]bool, numSourceBlocks),
+		remaining:  numSourceBlocks,
+		done:       make(chan struct{}),
+	}
+	if cfg != nil && cfg.RepairESIs != nil {
+		r.repairESIs = cfg.RepairESIs
+	}
+	if r.remaining == 0 {
+		close(r.done)
+	}
+	return r
+}
+
+// Receive feeds the given symbols received from upstream into the decoder,
+// and relays them as described in Relay.
+//
+// Receive returns the first error returned by the send function or
+// encountered while generating repair symbols.
+func (r *Relay) Receive(symbols []raptorq.Symbol) (err error) {
+	status := r.dec.DecodeBatch(symbols)
+	for i, symbol := range symbols {
+		if r.IsComplete(symbol.SBN) {
+			continue
+		}
+		switch status[i] {
+		case raptorq.SymbolAccepted:
+			err = r.send(symbol)
+		case raptorq.SymbolNotNeeded:
+			if r.dec.IsSourceBlockReady(symbol.SBN) {
+				err = r.Generate(symbol.SBN, 1)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Generate generates and sends the given number of new repair symbols for
+// the given source block, which must have been recovered.
+func (r *Relay) Generate(sbn uint8, count int) (err error) {
+	for i := 0; i < count; i++ {
+		var symbol raptorq.Symbol
+		if symbol, err = r.nextRepairSymbol(sbn); err != nil {
+			return
+		}
+		if err = r.send(symbol); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *Relay) nextRepairSymbol(sbn uint8) (
+	symbol raptorq.Symbol, err error,
+) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		err = errors.New("relay closed")
+		return
+	}
+	if int(sbn) >= len(r.generated) {
+		err = errors.New("source block number out of range")
+		return
+	}
+	if r.reencoder == nil {
+		if r.reencoder, err = r.dec.Reencoder(); err != nil {
+			return
+		}
+	}
+	esi, ok := r.repairESIs(sbn, r.generated[sbn],
+		r.reencoder.MaxSymbols(sbn))
+	if !ok {
+		err = errors.New("repair symbol ESIs exhausted")
+		return
+	}
+	buf := make([]byte, r.reencoder.SymbolSize())
+	if _, err = r.reencoder.Encode(sbn, esi, buf); err != nil {
+		return
+	}
+	r.generated[sbn]++
+	symbol = raptorq.Symbol{SBN: sbn, ESI: esi, Data: buf}
+	return
+}
+
+// Complete marks the given source block as complete downstream, i.e. all
+// downstream peers have recovered it.  The relay stops sending symbols of
+// the source block.
+func (r *Relay) Complete(sbn uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if int(sbn) >= len(r.complete) || r.complete[sbn] {
+		return
+	}
+	r.complete[sbn] = true
+	if r.reencoder != nil {
+		r.reencoder.FreeSourceBlock(sbn)
+	}
+	r.remaining--
+	if r.remaining == 0 {
+		close(r.done)
+	}
+}
+
+// IsComplete returns whether the given source block has been marked as
+// complete downstream.  Out-of-range source blocks are considered complete.
+func (r *Relay) IsComplete(sbn uint8) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return int(sbn) >= len(r.complete) || r.complete[sbn]
+}
+
+// Done returns a channel that is closed once all source blocks have been
+// marked as complete downstream.
+func (r *Relay) Done() <-chan struct{} {
+	return r.done
+}
+
+// Close closes the relay.  It does not close the decoder.
+func (r *Relay) Close() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		err = errors.New("relay already closed")
+		return
+	}
+	r.closed = true
+	if r.reencoder != nil {
+		err = r.reencoder.Close()
+		r.reencoder = nil
+	}
+	return
+}
@@ -0,0 +1,98 @@
+package relay
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// lossyLink is an in-memory network link that drops symbols at random.
+type lossyLink struct {
+	rng      *rand.Rand
+	lossRate float64
+	queue    []raptorq.Symbol
+}
+
+func (l *lossyLink) send(symbol raptorq.Symbol) error {
+	if l.rng.Float64() >= l.lossRate {
+		data := append([]byte(nil), symbol.Data...)
+		l.queue = append(l.queue, raptorq.Symbol{
+			SBN: symbol.SBN, ESI: symbol.ESI, Data: data,
+		})
+	}
+	return nil
+}
+
+func (l *lossyLink) drain() (symbols []raptorq.Symbol) {
+	symbols, l.queue = l.queue, nil
+	return
+}
+
+func TestRelay(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	object := make([]byte, 100000)
+	rng.Read(object)
+	enc, err := defaults.ReedSolomonEncoderFactory().New(object,
+		1000, 1000, 30000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	relayDec, err := defaults.ReedSolomonDecoderFactory().New(enc.CommonOTI(),
+		enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer relayDec.Close()
+	dec, err := defaults.ReedSolomonDecoderFactory().New(enc.CommonOTI(),
+		enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+
+	upstream := &lossyLink{rng: rng, lossRate: 0.2}
+	downstream := &lossyLink{rng: rng, lossRate: 0.2}
+	r := New(relayDec, downstream.send, nil)
+	defer r.Close()
+
+	var esi uint32
+	for round := 0; ; round++ {
+		if round > 1000 {
+			t.Fatal("downstream decoder did not recover the object")
+		}
+		select {
+		case <-r.Done():
+		default:
+			for sbn := uint8(0); sbn < enc.NumSourceBlocks(); sbn++ {
+				buf := make([]byte, enc.SymbolSize())
+				if _, err := enc.Encode(sbn, esi, buf); err != nil {
+					t.Fatal(err)
+				}
+				upstream.send(raptorq.Symbol{SBN: sbn, ESI: esi, Data: buf})
+			}
+			esi++
+			if err := r.Receive(upstream.drain()); err != nil {
+				t.Fatal(err)
+			}
+			dec.DecodeBatch(downstream.drain())
+			for sbn := uint8(0); sbn < dec.NumSourceBlocks(); sbn++ {
+				if dec.IsSourceBlockReady(sbn) {
+					r.Complete(sbn)
+				}
+			}
+			continue
+		}
+		break
+	}
+	recovered := make([]byte, dec.TransferLength())
+	if _, err := dec.SourceObject(recovered); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, object) {
+		t.Error("recovered object differs from the original")
+	}
+}
@@ -0,0 +1,159 @@
+// Package schedule generates transmission schedules of encoding symbols.
+package schedule
+
+import (
+	"math"
+
+	"github.com/harmony-one/go-raptorq/pkg/esialloc"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// Entry identifies one encoding symbol to send.
+type Entry struct {
+	SBN uint8
+	ESI uint32
+}
+
+// Iterator yields the encoding symbols to send, in order.
+type Iterator interface {
+	// Next returns the next encoding symbol to send, or ok = false if there
+	// are no more.
+	Next() (e Entry, ok bool)
+}
+
+// Mode selects which encoding symbols a schedule includes.
+type Mode int
+
+const (
+	// SourceFirst sends the source symbols of each source block first,
+	// followed by repair symbols.
+	SourceFirst Mode = iota
+
+	// RepairOnly sends repair symbols only, e.g. when the source symbols
+	// are sent by someone else or are already known by receivers.
+	RepairOnly
+)
+
+// Config is the schedule configuration.
+type Config struct {
+	// Redundancy is the ratio of extra encoding symbols sent for each source
+	// block, relative to its number of source symbols.  For example, 0.1
+	// sends 110 encoding symbols for a source block of 100 source symbols.
+	Redundancy float64
+
+	// Mode selects which encoding symbols to send.
+	Mode Mode
+
+	// Repair, if not nil, restricts repair symbols to the given ESI
+	// allocation, starting at the first repair ESI of each source block.
+	// Otherwise repair symbols use consecutive ESIs.
+	Repair *esialloc.Allocation
+}
+
+// Schedule is a finite sequence of encoding symbols to send, interleaved
+// across source blocks, so that a burst of losses is spread over all source
+// blocks instead of wiping out one of them.
+//
+// The schedule visits source blocks in a round-robin fashion, taking the next
+// encoding symbol from each source block in turn.
+type Schedule struct {
+	cfg        Config
+	enc        raptorq.Encoder
+	counts     []uint32
+	maxCount   uint32
+	len        int
+	round      uint32
+	sbn        int
+	maxSymbols []uint32
+}
+
+// New returns a new schedule for the given encoder.
+func New(enc raptorq.Encoder, cfg Config) *Schedule {
+	s := &Schedule{cfg: cfg, enc: enc}
+	numSourceBlocks := int(enc.NumSourceBlocks())
+	s.counts = make([]uint32, numSourceBlocks)
+	s.maxSymbols = make([]uint32, numSourceBlocks)
+	for sbn := range s.counts {
+		k := float64(enc.NumSourceSymbols(uint8(sbn)))
+		count := uint32(math.Ceil(k * (1 + cfg.Redundancy)))
+		s.maxSymbols[sbn] = enc.MaxSymbols(uint8(sbn))
+		// Cap the count to what esi can yield, so that Len is exact.
+		if available := s.available(uint8(sbn)); count > available {
+			count = available
+		}
+		s.counts[sbn] = count
+		s.len += int(count)
+		if count > s.maxCount {
+			s.maxCount = count
+		}
+	}
+	return s
+}
+
+// Len returns the total number of encoding symbols in the schedule.
+func (s *Schedule) Len() int {
+	return s.len
+}
+
+// Next returns the next encoding symbol to send, or ok = false if the schedule
+// has been exhausted.
+func (s *Schedule) Next() (e Entry, ok bool) {
+	for s.round < s.maxCount {
+		for s.sbn < len(s.counts) {
+			sbn := uint8(s.sbn)
+			s.sbn++
+			if s.round >= s.counts[sbn] {
+				continue
+			}
+			var esi uint32
+			if esi, ok = s.esi(sbn, s.round); ok {
+				e = Entry{SBN: sbn, ESI: esi}
+				return
+			}
+		}
+		s.sbn = 0
+		s.round++
+	}
+	return
+}
+
+// esi returns the ESI of the i-th encoding symbol sent for the given source
+// block.
+func (s *Schedule) esi(sbn uint8, i uint32) (esi uint32, ok bool) {
+	k := uint32(s.enc.NumSourceSymbols(sbn))
+	if s.cfg.Mode == SourceFirst {
+		if i < k {
+			return i, true
+		}
+		i -= k
+	}
+	if s.cfg.Repair != nil {
+		return s.cfg.Repair.StartingAt(k).ESI(i, s.maxSymbols[sbn])
+	}
+	if uint64(k)+uint64(i) >= uint64(s.maxSymbols[sbn]) {
+		return
+	}
+	return k + i, true
+}
+
+// available returns the number of encoding symbols esi yields for the given
+// source block, that is, the number of i for which it returns ok = true.
+func (s *Schedule) available(sbn uint8) (n uint32) {
+	k := uint32(s.enc.NumSourceSymbols(sbn))
+	if s.cfg.Mode == SourceFirst {
+		n = k
+	}
+	switch {
+	case s.cfg.Repair != nil:
+		n += s.cfg.Repair.StartingAt(k).Len(s.maxSymbols[sbn])
+	case s.maxSymbols[sbn] > k:
+		n += s.maxSymbols[sbn] - k
+	}
+	return
+}
+
+// Reset rewinds the schedule to its beginning.
+func (s *Schedule) Reset() {
+	s.round = 0
+	s.sbn = 0
+}
@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/pkg/esialloc"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// fakeEncoder provides only the methods a schedule uses.
+type fakeEncoder struct {
+	raptorq.Encoder
+	k          []uint16
+	maxSymbols uint32
+}
+
+func (e *fakeEncoder) NumSourceBlocks() uint8            { return uint8(len(e.k)) }
+func (e *fakeEncoder) NumSourceSymbols(sbn uint8) uint16 { return e.k[sbn] }
+func (e *fakeEncoder) MaxSymbols(sbn uint8) uint32       { return e.maxSymbols }
+
+// drain returns all entries of the schedule, checking them against Len.
+func drain(t *testing.T, s *Schedule) (entries []Entry) {
+	for {
+		e, ok := s.Next()
+		if !ok {
+			break
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != s.Len() {
+		t.Errorf("%d entries, Len() = %d", len(entries), s.Len())
+	}
+	return
+}
+
+func TestInterleaving(t *testing.T) {
+	enc := &fakeEncoder{k: []uint16{3, 2}, maxSymbols: 100}
+	s := New(enc, Config{Redundancy: 0.5})
+	want := []Entry{
+		{0, 0}, {1, 0},
+		{0, 1}, {1, 1},
+		{0, 2}, {1, 2},
+		{0, 3}, // block 1 is done after ceil(2 × 1.5) = 3 symbols
+		{0, 4},
+	}
+	if got := drain(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	s.Reset()
+	if got := drain(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries after Reset = %v, want %v", got, want)
+	}
+}
+
+func TestRepairOnly(t *testing.T) {
+	enc := &fakeEncoder{k: []uint16{2}, maxSymbols: 100}
+	s := New(enc, Config{Redundancy: 0.5, Mode: RepairOnly})
+	want := []Entry{{0, 2}, {0, 3}, {0, 4}}
+	if got := drain(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
+
+func TestAllocation(t *testing.T) {
+	enc := &fakeEncoder{k: []uint16{4}, maxSymbols: 100}
+	a, err := esialloc.ByIndex(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(enc, Config{Redundancy: 1, Repair: &a})
+	want := []Entry{
+		{0, 0}, {0, 1}, {0, 2}, {0, 3}, // source symbols
+		{0, 5}, {0, 8}, {0, 11}, {0, 14}, // every third repair symbol
+	}
+	if got := drain(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
+
+func TestCap(t *testing.T) {
+	enc := &fakeEncoder{k: []uint16{4, 4}, maxSymbols: 10}
+	a, err := esialloc.ByIndex(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		cfg  Config
+		want int
+	}{
+		{"consecutive", Config{Redundancy: 5}, 2 * 10},
+		{"allocation", Config{Redundancy: 5, Repair: &a}, 2 * (4 + 3)},
+		{"repair only", Config{Redundancy: 5, Mode: RepairOnly}, 2 * 6},
+		{"repair only allocation",
+			Config{Redundancy: 5, Mode: RepairOnly, Repair: &a}, 2 * 3},
+	} {
+		entries := drain(t, New(enc, tc.cfg))
+		if len(entries) != tc.want {
+			t.Errorf("%s: %d entries, want %d", tc.name, len(entries),
+				tc.want)
+		}
+		for _, e := range entries {
+			if e.ESI >= enc.maxSymbols {
+				t.Errorf("%s: ESI %d beyond MaxSymbols", tc.name, e.ESI)
+			}
+		}
+	}
+}
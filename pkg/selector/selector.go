@@ -0,0 +1,79 @@
+// Package selector provides encoder and decoder factories that pick the
+// Reed-Solomon code for source objects of few source symbols, and RaptorQ
+// otherwise.
+//
+// For such objects, the reception overhead and set-up cost of RaptorQ dominate,
+// whereas Reed-Solomon recovers a source block from any K encoding symbols.
+package selector
+
+import (
+	"errors"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// DefaultMaxSmallSymbols is the default largest number of source symbols for
+// which the Reed-Solomon code is picked.
+const DefaultMaxSmallSymbols = 16
+
+// EncoderFactory creates Reed-Solomon encoders for small source objects, and
+// RaptorQ encoders for others.
+type EncoderFactory struct {
+	// Small creates Reed-Solomon encoders.
+	Small raptorq.EncoderFactory
+
+	// Large creates RaptorQ encoders.
+	Large raptorq.EncoderFactory
+
+	// MaxSmallSymbols is the largest number of source symbols of a source
+	// object for which Small is used.  If zero, DefaultMaxSmallSymbols is
+	// used.
+	MaxSmallSymbols uint16
+}
+
+// New returns an encoder of the given source object, created by Small if the
+// source object has at most MaxSmallSymbols source symbols of the given size,
+// or by Large otherwise.
+func (f *EncoderFactory) New(input []byte, symbolSize uint16,
+	minSubSymbolSize uint16, maxSubBlockSize uint32, alignment uint8,
+) (enc raptorq.Encoder, err error) {
+	if symbolSize == 0 {
+		err = errors.New("symbol size must not be zero")
+		return
+	}
+	maxSmall := uint64(f.MaxSmallSymbols)
+	if maxSmall == 0 {
+		maxSmall = DefaultMaxSmallSymbols
+	}
+	factory := f.Large
+	numSymbols := (uint64(len(input)) + uint64(symbolSize) - 1) /
+		uint64(symbolSize)
+	if numSymbols <= maxSmall {
+		factory = f.Small
+	}
+	return factory.New(input, symbolSize, minSubSymbolSize, maxSubBlockSize,
+		alignment)
+}
+
+// DecoderFactory creates Reed-Solomon or RaptorQ decoders depending on the
+// OTIs received.
+type DecoderFactory struct {
+	// Small creates Reed-Solomon decoders.
+	Small raptorq.DecoderFactory
+
+	// Large creates RaptorQ decoders.
+	Large raptorq.DecoderFactory
+}
+
+// New returns a decoder for the given OTIs.
+//
+// The top octet of the scheme-specific OTI tells the codes apart: it is the
+// number of source blocks, at least 1, for RaptorQ, and zero for Reed-Solomon.
+func (f *DecoderFactory) New(commonOTI uint64, schemeSpecificOTI uint32) (
+	dec raptorq.Decoder, err error,
+) {
+	if schemeSpecificOTI>>24 == 0 {
+		return f.Small.New(commonOTI, schemeSpecificOTI)
+	}
+	return f.Large.New(commonOTI, schemeSpecificOTI)
+}
@@ -0,0 +1,52 @@
+package selector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harmony-one/go-raptorq/internal/impl/rs"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+)
+
+// largeFactory records whether it has been asked for an encoder or decoder.
+type largeFactory struct {
+	used bool
+}
+
+func (f *largeFactory) New(input []byte, symbolSize uint16,
+	minSubSymbolSize uint16, maxSubBlockSize uint32, alignment uint8,
+) (raptorq.Encoder, error) {
+	f.used = true
+	return nil, errors.New("large factory used")
+}
+
+func TestEncoderFactory(t *testing.T) {
+	large := &largeFactory{}
+	f := &EncoderFactory{Small: &rs.EncoderFactory{}, Large: large}
+	enc, err := f.New(make([]byte, 16*100), 100, 100, 1<<20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Close()
+	if large.used {
+		t.Error("16 source symbols not encoded with Reed-Solomon")
+	}
+	f.New(make([]byte, 17*100), 100, 100, 1<<20, 1)
+	if !large.used {
+		t.Error("17 source symbols encoded with Reed-Solomon")
+	}
+}
+
+func TestDecoderFactory(t *testing.T) {
+	enc, err := (&rs.EncoderFactory{}).New(make([]byte, 300), 100, 100, 1<<20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	f := &DecoderFactory{Small: &rs.DecoderFactory{}, Large: nil}
+	dec, err := f.New(enc.CommonOTI(), enc.SchemeSpecificOTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec.Close()
+}
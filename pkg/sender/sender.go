@@ -0,0 +1,170 @@
+// Package sender provides a paced sender of encoding symbols.
+package sender
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/schedule"
+)
+
+// SendFunc sends one packet.  It must not retain the packet after returning.
+type SendFunc func(packet []byte) error
+
+// Config is the sender configuration.
+type Config struct {
+	// Rate is the sending rate, in packets per second.  Zero means no
+	// pacing.
+	Rate float64
+
+	// Burst is the maximum number of packets sent back to back when the
+	// sender has fallen behind the rate.  Zero means 1.
+	Burst int
+
+	// Budget is the maximum number of packets to send.  Zero means no limit
+	// other than the schedule length.
+	Budget int
+
+	// Header is prepended to each packet, before the FEC Payload ID, e.g. to
+	// identify the source object.
+	Header []byte
+}
+
+// Stats is the sender statistics.
+type Stats struct {
+	// Packets is the number of packets sent.
+	Packets int
+
+	// Octets is the total size of packets sent, in octets.
+	Octets int64
+
+	// Completed is whether the sender stopped on the completion signal.
+	Completed bool
+
+	// Exhausted is whether the sender stopped because the schedule or the
+	// budget ran out.
+	Exhausted bool
+}
+
+// Sender sends encoding symbols generated by an encoder following a
+// schedule, such as a schedule.Schedule or a feedback.Controller, as packets
+// of the form:
+//
+//	Header || FEC Payload ID || encoding symbol
+//
+// pacing them with a token bucket.
+type Sender struct {
+	enc   raptorq.Encoder
+	sched schedule.Iterator
+	send  SendFunc
+	cfg   Config
+	mutex sync.Mutex
+	stats Stats
+}
+
+// New returns a new sender that sends packets using the given function.
+func New(
+	enc raptorq.Encoder, sched schedule.Iterator, send SendFunc, cfg Config,
+) *Sender {
+	return &Sender{enc: enc, sched: sched, send: send, cfg: cfg}
+}
+
+// NewPacketConn returns a new sender that sends packets to the given address
+// over the given packet connection.
+func NewPacketConn(
+	enc raptorq.Encoder, sched schedule.Iterator, conn net.PacketConn,
+	addr net.Addr, cfg Config,
+) *Sender {
+	return New(enc, sched, func(packet []byte) error {
+		_, err := conn.WriteTo(packet, addr)
+		return err
+	}, cfg)
+}
+
+// Run sends packets until the schedule or the budget runs out, the done
+// channel is closed (e.g. when receivers signal completion), or the context
+// is done.
+//
+// Run returns nil unless it stops on an error returned by the encoder or the
+// send function, or on the context being done.
+func (s *Sender) Run(ctx context.Context, done <-chan struct{}) (err error) {
+	headerSize := len(s.cfg.Header)
+	packet := make([]byte,
+		headerSize+raptorq.FECPayloadIDSize+int(s.enc.SymbolSize()))
+	copy(packet, s.cfg.Header)
+	var bucket *tokenBucket
+	if s.cfg.Rate > 0 {
+		bucket = newTokenBucket(s.cfg.Rate, s.cfg.Burst, time.Now())
+	}
+	for sent := 0; s.cfg.Budget == 0 || sent < s.cfg.Budget; sent++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			s.setCompleted()
+			return nil
+		default:
+		}
+		entry, ok := s.sched.Next()
+		if !ok {
+			break
+		}
+		if bucket != nil {
+			if delay := bucket.delay(time.Now()); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-done:
+					timer.Stop()
+					s.setCompleted()
+					return nil
+				case <-timer.C:
+				}
+			}
+		}
+		err = raptorq.PutFECPayloadID(packet[headerSize:], entry.SBN,
+			entry.ESI)
+		if err != nil {
+			return err
+		}
+		symbol := packet[headerSize+raptorq.FECPayloadIDSize:]
+		written, err := s.enc.Encode(entry.SBN, entry.ESI, symbol)
+		if err != nil {
+			return err
+		}
+		if written != uint(len(symbol)) {
+			return errors.New("encoder returned a short symbol")
+		}
+		if err = s.send(packet); err != nil {
+			return err
+		}
+		s.mutex.Lock()
+		s.stats.Packets++
+		s.stats.Octets += int64(len(packet))
+		s.mutex.Unlock()
+	}
+	s.mutex.Lock()
+	s.stats.Exhausted = true
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *Sender) setCompleted() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stats.Completed = true
+}
+
+// Stats returns the sender statistics so far.  It is safe to call Stats
+// while Run is in progress.
+func (s *Sender) Stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stats
+}
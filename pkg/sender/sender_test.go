@@ -0,0 +1,154 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/schedule"
+)
+
+// fakeEncoder generates symbols filled with their ESI.
+type fakeEncoder struct {
+	raptorq.Encoder
+}
+
+func (*fakeEncoder) SymbolSize() uint16 { return 4 }
+
+func (*fakeEncoder) Encode(sbn uint8, esi uint32, buf []byte) (
+	written uint, err error,
+) {
+	for i := range buf[:4] {
+		buf[i] = byte(esi)
+	}
+	return 4, nil
+}
+
+// entries is a schedule of the given number of symbols of source block 1.
+type entries struct {
+	next, n uint32
+}
+
+func (e *entries) Next() (entry schedule.Entry, ok bool) {
+	if e.next == e.n {
+		return
+	}
+	entry = schedule.Entry{SBN: 1, ESI: e.next}
+	e.next++
+	return entry, true
+}
+
+// collect returns a send function that appends packets to *packets.
+func collect(packets *[][]byte) SendFunc {
+	return func(packet []byte) error {
+		*packets = append(*packets, append([]byte(nil), packet...))
+		return nil
+	}
+}
+
+func TestPacketFormat(t *testing.T) {
+	var packets [][]byte
+	s := New(&fakeEncoder{}, &entries{n: 2}, collect(&packets),
+		Config{Header: []byte("hdr")})
+	if err := s.Run(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]byte{
+		[]byte("hdr\x01\x00\x00\x00\x00\x00\x00\x00"),
+		[]byte("hdr\x01\x00\x00\x01\x01\x01\x01\x01"),
+	}
+	if len(packets) != len(want) {
+		t.Fatalf("%d packets, want %d", len(packets), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(packets[i], want[i]) {
+			t.Errorf("packet %d = %q, want %q", i, packets[i], want[i])
+		}
+	}
+	if stats := s.Stats(); stats.Packets != 2 || stats.Octets != 22 ||
+		!stats.Exhausted || stats.Completed {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	var packets [][]byte
+	s := New(&fakeEncoder{}, &entries{n: 10}, collect(&packets),
+		Config{Budget: 3})
+	if err := s.Run(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if stats := s.Stats(); len(packets) != 3 || stats.Packets != 3 ||
+		!stats.Exhausted {
+		t.Errorf("%d packets sent, Stats() = %+v", len(packets), stats)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	done := make(chan struct{})
+	var packets [][]byte
+	send := collect(&packets)
+	s := New(&fakeEncoder{}, &entries{n: 10}, func(packet []byte) error {
+		if err := send(packet); err != nil {
+			return err
+		}
+		if len(packets) == 2 {
+			close(done)
+		}
+		return nil
+	}, Config{})
+	if err := s.Run(context.Background(), done); err != nil {
+		t.Fatal(err)
+	}
+	if stats := s.Stats(); stats.Packets != 2 || !stats.Completed ||
+		stats.Exhausted {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestESIOutOfRange(t *testing.T) {
+	var packets [][]byte
+	s := New(&fakeEncoder{}, &entries{next: raptorq.MaxESI, n: raptorq.MaxESI + 2},
+		collect(&packets), Config{})
+	if err := s.Run(context.Background(), nil); err == nil {
+		t.Error("out-of-range ESI sent")
+	}
+	if len(packets) != 1 {
+		t.Errorf("%d packets sent, want 1", len(packets))
+	}
+}
+
+func TestPacing(t *testing.T) {
+	var packets [][]byte
+	s := New(&fakeEncoder{}, &entries{n: 6}, collect(&packets),
+		Config{Rate: 100, Burst: 2})
+	start := time.Now()
+	if err := s.Run(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	// The first 2 packets go out as a burst, and the other 4 at 10ms apart.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("6 packets took %v, want about 40ms", elapsed)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	tb := newTokenBucket(10, 2, now)
+	for i, want := range []time.Duration{0, 0, 100 * time.Millisecond} {
+		if got := tb.delay(now); got != want {
+			t.Errorf("event %d: delay %v, want %v", i, got, want)
+		}
+	}
+	// The third event was scheduled 100ms out; after 250ms the bucket has
+	// refilled 1.5 tokens.
+	now = now.Add(250 * time.Millisecond)
+	for i, want := range []time.Duration{0, 50 * time.Millisecond} {
+		if got := tb.delay(now); got != want {
+			t.Errorf("event %d after refill: delay %v, want %v", i, got,
+				want)
+		}
+	}
+}
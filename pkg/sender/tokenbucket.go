@@ -0,0 +1,36 @@
+package sender
+
+import "time"
+
+// tokenBucket paces events to a given rate, allowing bursts of up to a given
+// size.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate: rate, burst: float64(burst), tokens: float64(burst), last: now,
+	}
+}
+
+// delay takes one token, and returns how long to wait before the event the
+// token is for.
+func (tb *tokenBucket) delay(now time.Time) time.Duration {
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+	tb.tokens--
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
@@ -0,0 +1,246 @@
+// Package symauth authenticates packets of encoding symbols, so that
+// receivers drop forged or corrupted symbols before they reach the decoder.
+//
+// A decoder that is fed a bad symbol does not notice; it decodes the source
+// block to garbage.  Checksums catch accidental corruption only, so the
+// sender appends a tag to each packet:
+//
+//	Header || FEC Payload ID || encoding symbol || Tag
+//
+// where Tag is a MAC or a signature over all preceding octets, thus covering
+// the object ID, the OTIs, the source block number, the encoding symbol ID and
+// the encoding symbol itself.
+package symauth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"sync/atomic"
+
+	"github.com/harmony-one/go-raptorq/internal/blake3"
+	"github.com/harmony-one/go-raptorq/pkg/sender"
+)
+
+// Signer computes tags.
+type Signer interface {
+	// TagSize returns the size of tags, in octets.
+	TagSize() int
+
+	// Sign returns the tag of the given message.
+	Sign(message []byte) (tag []byte)
+}
+
+// Verifier checks tags.
+type Verifier interface {
+	// TagSize returns the size of tags, in octets.
+	TagSize() int
+
+	// Verify returns whether the given tag is valid for the given message.
+	Verify(message []byte, tag []byte) bool
+}
+
+// HMACSHA256 is a Signer and Verifier of HMAC-SHA256 tags under a key shared
+// by the sender and receivers.
+type HMACSHA256 struct {
+	key []byte
+}
+
+// KeySize is the size of the keys of BLAKE3, and the minimum size of the keys
+// of HMACSHA256, in octets.
+const KeySize = 32
+
+// NewHMACSHA256 returns an HMAC-SHA256 Signer and Verifier under the given key.
+//
+// NewHMACSHA256 returns an error if the key is shorter than KeySize, which RFC
+// 2104 discourages.
+func NewHMACSHA256(key []byte) (m *HMACSHA256, err error) {
+	if len(key) < KeySize {
+		err = errors.New("HMAC-SHA256 key too short")
+		return
+	}
+	m = &HMACSHA256{key: append([]byte(nil), key...)}
+	return
+}
+
+// TagSize returns the size of HMAC-SHA256 tags, in octets.
+func (*HMACSHA256) TagSize() int {
+	return sha256.Size
+}
+
+// Sign returns the HMAC-SHA256 of the given message.
+func (m *HMACSHA256) Sign(message []byte) []byte {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// Verify returns whether the given tag is the HMAC-SHA256 of the given
+// message.
+func (m *HMACSHA256) Verify(message []byte, tag []byte) bool {
+	return hmac.Equal(m.Sign(message), tag)
+}
+
+// BLAKE3 is a Signer and Verifier of keyed BLAKE3 tags under a key shared by
+// the sender and receivers.  It is faster than HMAC-SHA256 in software.
+type BLAKE3 struct {
+	key [blake3.KeySize]byte
+}
+
+// NewBLAKE3 returns a keyed BLAKE3 Signer and Verifier under the given key.
+//
+// NewBLAKE3 returns an error unless the key is KeySize octets long.
+func NewBLAKE3(key []byte) (m *BLAKE3, err error) {
+	if len(key) != blake3.KeySize {
+		err = errors.New("BLAKE3 key size must be 32 octets")
+		return
+	}
+	m = &BLAKE3{}
+	copy(m.key[:], key)
+	return
+}
+
+// TagSize returns the size of keyed BLAKE3 tags, in octets.
+func (*BLAKE3) TagSize() int {
+	return blake3.Size
+}
+
+// Sign returns the keyed BLAKE3 hash of the given message.
+func (m *BLAKE3) Sign(message []byte) []byte {
+	tag := blake3.KeyedSum256(&m.key, message)
+	return tag[:]
+}
+
+// Verify returns whether the given tag is the keyed BLAKE3 hash of the given
+// message.
+func (m *BLAKE3) Verify(message []byte, tag []byte) bool {
+	return subtle.ConstantTimeCompare(m.Sign(message), tag) == 1
+}
+
+// Ed25519Signer is a Signer of Ed25519 signatures.  Unlike MACs, signatures
+// let receivers authenticate the sender without being able to forge packets
+// themselves, but cost much more to compute and verify.
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer returns a Signer under the given private key.
+//
+// NewEd25519Signer returns an error unless the key is
+// ed25519.PrivateKeySize octets long.
+func NewEd25519Signer(key ed25519.PrivateKey) (s *Ed25519Signer, err error) {
+	if len(key) != ed25519.PrivateKeySize {
+		err = errors.New("Ed25519 private key size must be 64 octets")
+		return
+	}
+	s = &Ed25519Signer{key: key}
+	return
+}
+
+// TagSize returns the size of Ed25519 signatures, in octets.
+func (*Ed25519Signer) TagSize() int {
+	return ed25519.SignatureSize
+}
+
+// Sign returns the Ed25519 signature of the given message.
+func (s *Ed25519Signer) Sign(message []byte) []byte {
+	return ed25519.Sign(s.key, message)
+}
+
+// Ed25519Verifier is a Verifier of Ed25519 signatures.
+type Ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+// NewEd25519Verifier returns a Verifier under the given public key.
+//
+// NewEd25519Verifier returns an error unless the key is
+// ed25519.PublicKeySize octets long.
+func NewEd25519Verifier(key ed25519.PublicKey) (v *Ed25519Verifier, err error) {
+	if len(key) != ed25519.PublicKeySize {
+		err = errors.New("Ed25519 public key size must be 32 octets")
+		return
+	}
+	v = &Ed25519Verifier{key: key}
+	return
+}
+
+// TagSize returns the size of Ed25519 signatures, in octets.
+func (*Ed25519Verifier) TagSize() int {
+	return ed25519.SignatureSize
+}
+
+// Verify returns whether the given tag is a valid Ed25519 signature of the
+// given message.
+func (v *Ed25519Verifier) Verify(message []byte, tag []byte) bool {
+	return len(tag) == ed25519.SignatureSize &&
+		ed25519.Verify(v.key, message, tag)
+}
+
+// Seal appends the tag of the given packet to it.
+func Seal(s Signer, packet []byte) []byte {
+	return append(packet, s.Sign(packet)...)
+}
+
+// Open verifies the tag at the end of the given packet, and returns the
+// packet without the tag.
+func Open(v Verifier, packet []byte) (payload []byte, err error) {
+	n := len(packet) - v.TagSize()
+	if n < 0 {
+		err = errors.New("packet too short")
+		return
+	}
+	if !v.Verify(packet[:n], packet[n:]) {
+		err = errors.New("packet authentication failed")
+		return
+	}
+	payload = packet[:n]
+	return
+}
+
+// SendFunc returns a sender.SendFunc that seals each packet before sending it
+// using the given function.
+//
+// Each sealed packet is a fresh copy, so send may retain it, and the returned
+// function may be called concurrently if s and send allow it.
+func SendFunc(s Signer, send sender.SendFunc) sender.SendFunc {
+	return func(packet []byte) error {
+		buf := make([]byte, len(packet), len(packet)+s.TagSize())
+		copy(buf, packet)
+		return send(Seal(s, buf))
+	}
+}
+
+// HandleFunc handles one packet, e.g. receiver.Receiver.HandlePacket.
+type HandleFunc func(packet []byte) error
+
+// Filter is a receiving front-end that verifies each packet and passes those
+// authentic, without their tag, on to the next handler.
+type Filter struct {
+	v       Verifier
+	next    HandleFunc
+	dropped uint64
+}
+
+// NewFilter returns a new filter passing authentic packets on to next.
+func NewFilter(v Verifier, next HandleFunc) *Filter {
+	return &Filter{v: v, next: next}
+}
+
+// HandlePacket verifies the given packet, and passes it on if authentic.
+// Otherwise it drops the packet and returns an error.
+func (f *Filter) HandlePacket(packet []byte) (err error) {
+	payload, err := Open(f.v, packet)
+	if err != nil {
+		atomic.AddUint64(&f.dropped, 1)
+		return
+	}
+	return f.next(payload)
+}
+
+// Dropped returns the number of packets dropped so far.
+func (f *Filter) Dropped() uint64 {
+	return atomic.LoadUint64(&f.dropped)
+}
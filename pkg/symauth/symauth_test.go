@@ -0,0 +1,151 @@
+package symauth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+)
+
+type pair struct {
+	name string
+	s    Signer
+	v    Verifier
+}
+
+func pairs(t *testing.T) []pair {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := []byte("0123456789abcdef0123456789abcdef")
+	hm, err := NewHMACSHA256(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b3, err := NewBLAKE3(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewEd25519Signer(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewEd25519Verifier(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []pair{
+		{"HMAC-SHA256", hm, hm},
+		{"BLAKE3", b3, b3},
+		{"Ed25519", signer, verifier},
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	packet := []byte("object ID, OTIs, FEC payload ID and symbol")
+	for _, p := range pairs(t) {
+		sealed := Seal(p.s, append([]byte(nil), packet...))
+		if len(sealed) != len(packet)+p.s.TagSize() {
+			t.Errorf("%s: sealed size %d", p.name, len(sealed))
+		}
+		payload, err := Open(p.v, sealed)
+		if err != nil || !bytes.Equal(payload, packet) {
+			t.Errorf("%s: Open() = %q, %v", p.name, payload, err)
+		}
+		for i := range sealed {
+			tampered := append([]byte(nil), sealed...)
+			tampered[i] ^= 0x01
+			if _, err := Open(p.v, tampered); err == nil {
+				t.Errorf("%s: tampered octet %d accepted", p.name, i)
+			}
+		}
+		if _, err := Open(p.v, sealed[:p.v.TagSize()-1]); err == nil {
+			t.Errorf("%s: short packet accepted", p.name)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	for _, p := range pairs(t) {
+		var received [][]byte
+		f := NewFilter(p.v, func(packet []byte) error {
+			received = append(received, append([]byte(nil), packet...))
+			return nil
+		})
+		send := SendFunc(p.s, func(packet []byte) error {
+			forged := append([]byte(nil), packet...)
+			forged[0] ^= 0xff
+			_ = f.HandlePacket(forged)
+			return f.HandlePacket(packet)
+		})
+		for _, packet := range []string{"first", "second"} {
+			if err := send([]byte(packet)); err != nil {
+				t.Fatalf("%s: send: %v", p.name, err)
+			}
+		}
+		if len(received) != 2 || string(received[0]) != "first" ||
+			string(received[1]) != "second" {
+			t.Errorf("%s: received %q", p.name, received)
+		}
+		if f.Dropped() != 2 {
+			t.Errorf("%s: Dropped() = %d, want 2", p.name, f.Dropped())
+		}
+	}
+}
+
+func TestKeySize(t *testing.T) {
+	key := make([]byte, KeySize+1)
+	if _, err := NewHMACSHA256(key[:KeySize-1]); err == nil {
+		t.Error("short HMAC-SHA256 key accepted")
+	}
+	if _, err := NewHMACSHA256(key); err != nil {
+		t.Errorf("long HMAC-SHA256 key rejected: %v", err)
+	}
+	for _, n := range []int{KeySize - 1, KeySize + 1} {
+		if _, err := NewBLAKE3(key[:n]); err == nil {
+			t.Errorf("%d-octet BLAKE3 key accepted", n)
+		}
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []ed25519.PrivateKey{nil, priv[:ed25519.SeedSize],
+		append(priv, 0)} {
+		if _, err := NewEd25519Signer(key); err == nil {
+			t.Errorf("%d-octet Ed25519 private key accepted", len(key))
+		}
+	}
+	for _, key := range []ed25519.PublicKey{nil, pub[:len(pub)-1],
+		append(pub, 0)} {
+		if _, err := NewEd25519Verifier(key); err == nil {
+			t.Errorf("%d-octet Ed25519 public key accepted", len(key))
+		}
+	}
+}
+
+func TestSendFuncCopies(t *testing.T) {
+	key := make([]byte, KeySize)
+	m, err := NewBLAKE3(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent [][]byte
+	send := SendFunc(m, func(packet []byte) error {
+		// Retain the packet, which SendFunc allows.
+		sent = append(sent, packet)
+		return nil
+	})
+	for _, packet := range []string{"first", "second"} {
+		if err := send([]byte(packet)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, want := range []string{"first", "second"} {
+		if payload, err := Open(m, sent[i]); err != nil ||
+			string(payload) != want {
+			t.Errorf("packet %d = %q, %v, want %q", i, payload, err, want)
+		}
+	}
+}
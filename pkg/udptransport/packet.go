@@ -0,0 +1,35 @@
+package udptransport
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// ChecksumSize is the size of the packet checksum, in octets.
+const ChecksumSize = 4
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// seal appends the checksum of the given packet to it.
+func seal(packet []byte) []byte {
+	var checksum [ChecksumSize]byte
+	binary.BigEndian.PutUint32(checksum[:], crc32.Checksum(packet, castagnoli))
+	return append(packet, checksum[:]...)
+}
+
+// open verifies the checksum at the end of the given packet, and returns the
+// packet without the checksum.
+func open(packet []byte) (payload []byte, err error) {
+	if len(packet) < ChecksumSize {
+		err = errors.New("packet too short")
+		return
+	}
+	payload = packet[:len(packet)-ChecksumSize]
+	checksum := binary.BigEndian.Uint32(packet[len(payload):])
+	if crc32.Checksum(payload, castagnoli) != checksum {
+		payload = nil
+		err = errors.New("packet checksum mismatch")
+	}
+	return
+}
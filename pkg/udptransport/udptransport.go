@@ -0,0 +1,163 @@
+// Package udptransport transfers source objects over UDP, unicast or
+// multicast, using RaptorQ.
+//
+// Each UDP datagram carries one encoding symbol, in the form:
+//
+//	Object header || FEC Payload ID || encoding symbol || CRC-32C
+//
+// where the object header is that of the receiver package, and CRC-32C
+// covers all preceding octets.  Receivers drop datagrams with a wrong
+// checksum, so that corrupted symbols never reach the decoder.
+package udptransport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/raptorq"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+	"github.com/harmony-one/go-raptorq/pkg/registry"
+	"github.com/harmony-one/go-raptorq/pkg/schedule"
+	"github.com/harmony-one/go-raptorq/pkg/sender"
+)
+
+// DefaultSymbolSize is the default encoding symbol size, in octets, chosen so
+// that datagrams fit in a 1500-octet Ethernet MTU.
+const DefaultSymbolSize = 1400
+
+// DefaultMaxSubBlockSize is the default maximum sub-block size, in octets.
+const DefaultMaxSubBlockSize = 8 << 20
+
+// MaxDatagramSize is the size of the largest datagram, that is, the largest
+// UDP payload over IPv4: 65535 octets less the 20-octet IPv4 header and the
+// 8-octet UDP header.
+const MaxDatagramSize = 65507
+
+// MaxSymbolSize is the largest encoding symbol size, in octets, whose
+// datagrams fit in MaxDatagramSize.
+const MaxSymbolSize = MaxDatagramSize - receiver.HeaderSize -
+	raptorq.FECPayloadIDSize - ChecksumSize
+
+// SendConfig is the sender configuration.
+type SendConfig struct {
+	// Factory creates the encoder.  If nil, that of the default codec is
+	// used; Send fails if there is none, see defaults.LookupCodec.
+	Factory raptorq.EncoderFactory
+
+	// SymbolSize is the encoding symbol size, in octets.  If zero,
+	// DefaultSymbolSize is used.  It must not exceed MaxSymbolSize.
+	SymbolSize uint16
+
+	// MaxSubBlockSize is the maximum sub-block size, in octets.  If zero,
+	// DefaultMaxSubBlockSize is used.
+	MaxSubBlockSize uint32
+
+	// Redundancy is the ratio of repair symbols sent for each source block,
+	// relative to its number of source symbols.
+	Redundancy float64
+
+	// Rate is the sending rate, in datagrams per second.  Zero means no
+	// pacing, which likely overflows socket buffers for large objects.
+	Rate float64
+}
+
+// Send sends the given source object, identified by the given object ID, to
+// the given address, which may be a multicast group address.
+//
+// Send returns when all encoding symbols of the schedule implied by the
+// redundancy have been sent, or the context is done.
+func Send(
+	ctx context.Context, conn net.PacketConn, addr net.Addr,
+	id receiver.ObjectID, object []byte, cfg SendConfig,
+) (stats sender.Stats, err error) {
+	factory := cfg.Factory
+	if factory == nil {
+		var codec registry.Codec
+		if codec, err = defaults.LookupCodec(); err != nil {
+			return
+		}
+		factory = codec.EncoderFactory
+	}
+	symbolSize := cfg.SymbolSize
+	if symbolSize == 0 {
+		symbolSize = DefaultSymbolSize
+	}
+	if symbolSize > MaxSymbolSize {
+		err = errors.New("symbol size too large for a UDP datagram")
+		return
+	}
+	maxSubBlockSize := cfg.MaxSubBlockSize
+	if maxSubBlockSize == 0 {
+		maxSubBlockSize = DefaultMaxSubBlockSize
+	}
+	enc, err := factory.New(object, symbolSize, symbolSize, maxSubBlockSize, 1)
+	if err != nil {
+		return
+	}
+	defer enc.Close()
+	sched := schedule.New(enc, schedule.Config{Redundancy: cfg.Redundancy})
+	datagram := make([]byte, 0, MaxDatagramSize)
+	s := sender.New(enc, sched, func(packet []byte) error {
+		datagram = seal(append(datagram[:0], packet...))
+		_, err := conn.WriteTo(datagram, addr)
+		return err
+	}, sender.Config{
+		Rate:   cfg.Rate,
+		Burst:  16,
+		Header: receiver.HeaderFor(id, enc).Bytes(),
+	})
+	err = s.Run(ctx, nil)
+	stats = s.Stats()
+	return
+}
+
+// Receive receives datagrams from the given connection and feeds them into
+// the given receiver, until the context is done or the connection fails.
+//
+// Receive drops datagrams that are malformed or fail the checksum.
+func Receive(
+	ctx context.Context, conn net.PacketConn, r *receiver.Receiver,
+) (err error) {
+	buf := make([]byte, MaxDatagramSize)
+	for {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		// Wake up periodically to notice the context being done.
+		if err = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+			return
+		}
+		var n int
+		n, _, err = conn.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				r.Evict(time.Now())
+				continue
+			}
+			return
+		}
+		packet, openErr := open(buf[:n])
+		if openErr != nil {
+			continue
+		}
+		// The receiver rejects symbols of unknown or mismatched objects; a
+		// bad datagram must not stop reception of the others.
+		_ = r.HandlePacket(packet)
+	}
+}
+
+// ListenMulticast joins the given multicast group on the given network
+// interface (nil for the system default), and returns a connection for
+// receiving datagrams sent to the group.
+func ListenMulticast(group *net.UDPAddr, ifi *net.Interface) (
+	conn *net.UDPConn, err error,
+) {
+	if group == nil || !group.IP.IsMulticast() {
+		err = errors.New("not a multicast group address")
+		return
+	}
+	return net.ListenMulticastUDP("udp", ifi, group)
+}
@@ -0,0 +1,121 @@
+package udptransport
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-raptorq/pkg/defaults"
+	"github.com/harmony-one/go-raptorq/pkg/receiver"
+)
+
+// The transfer uses the Reed-Solomon code, available in all builds, over 2
+// source blocks of 50 source symbols each, sending 150 encoding symbols per
+// source block; any 50 of them recover the source block, so delivery survives
+// the loss of up to two thirds of the datagrams, far more than loopback drops
+// at the paced rate.
+const (
+	testSymbolSize      = 1000
+	testNumSourceBlocks = 2
+	testBlockSymbols    = 50
+	testRedundancy      = 2
+	testRate            = 2000
+)
+
+func testTransfer(
+	t *testing.T, recvConn net.PacketConn, addr net.Addr, sendAddr string,
+) {
+	object := make([]byte, testNumSourceBlocks*testBlockSymbols*testSymbolSize)
+	rand.New(rand.NewSource(1)).Read(object)
+	if c, ok := recvConn.(interface{ SetReadBuffer(int) error }); ok {
+		_ = c.SetReadBuffer(1 << 20)
+	}
+	delivered := make(chan []byte, 1)
+	r, err := receiver.New(receiver.Config{
+		Factory: defaults.ReedSolomonDecoderFactory(),
+		Deliver: func(id receiver.ObjectID, object []byte) {
+			if id == 42 {
+				delivered <- object
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go Receive(ctx, recvConn, r)
+
+	sendConn, err := net.ListenPacket("udp4", sendAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sendConn.Close()
+	stats, err := Send(ctx, sendConn, addr, 42, object, SendConfig{
+		Factory:         defaults.ReedSolomonEncoderFactory(),
+		SymbolSize:      testSymbolSize,
+		MaxSubBlockSize: testBlockSymbols * testSymbolSize,
+		Redundancy:      testRedundancy,
+		Rate:            testRate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testNumSourceBlocks * testBlockSymbols * (1 + testRedundancy)
+	if stats.Packets != want {
+		t.Errorf("sent %d packets, want %d", stats.Packets, want)
+	}
+	select {
+	case got := <-delivered:
+		if !bytes.Equal(got, object) {
+			t.Error("received object differs from the original")
+		}
+	case <-ctx.Done():
+		t.Fatal("object not delivered")
+	}
+}
+
+func TestUnicast(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	testTransfer(t, conn, conn.LocalAddr(), "127.0.0.1:0")
+}
+
+func TestMulticast(t *testing.T) {
+	group := &net.UDPAddr{IP: net.IPv4(239, 255, 82, 81), Port: 38181}
+	conn, err := ListenMulticast(group, loopbackInterface())
+	if err != nil {
+		t.Skip("multicast not available:", err)
+	}
+	defer conn.Close()
+	// Multicast datagrams loop back to local members of the group.
+	testTransfer(t, conn, group, ":0")
+}
+
+func TestSymbolSizeTooLarge(t *testing.T) {
+	_, err := Send(context.Background(), nil, nil, 42, make([]byte, 100),
+		SendConfig{
+			Factory:    defaults.ReedSolomonEncoderFactory(),
+			SymbolSize: MaxSymbolSize + 1,
+		})
+	if err == nil {
+		t.Error("symbol size larger than MaxSymbolSize accepted")
+	}
+}
+
+func loopbackInterface() *net.Interface {
+	interfaces, _ := net.Interfaces()
+	for _, ifi := range interfaces {
+		if ifi.Flags&net.FlagLoopback != 0 && ifi.Flags&net.FlagMulticast != 0 {
+			return &ifi
+		}
+	}
+	return nil
+}